@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"image/color"
+	"math"
+)
+
+// chartPoint is one point on a line chart. X is any increasing value, such
+// as days since the first point; Label is printed under the first and last
+// points.
+type chartPoint struct {
+	X     float64
+	Value float64
+	Label string
+}
+
+const chartMargin = 24
+
+// newLineChart draws points joined by lines, scaled to fit size. valueFormat
+// formats the min and max values shown on the left.
+func newLineChart(points []chartPoint, size fyne.Size, valueFormat string) fyne.CanvasObject {
+	background := canvas.NewRectangle(color.Transparent)
+	background.SetMinSize(size)
+
+	if len(points) == 0 {
+		return container.NewMax(background, canvas.NewText("No data", theme.ForegroundColor()))
+	}
+
+	minX, maxX := points[0].X, points[0].X
+	minY, maxY := points[0].Value, points[0].Value
+	for _, p := range points {
+		minX = math.Min(minX, p.X)
+		maxX = math.Max(maxX, p.X)
+		minY = math.Min(minY, p.Value)
+		maxY = math.Max(maxY, p.Value)
+	}
+	if maxX == minX {
+		maxX = minX + 1
+	}
+	if maxY == minY {
+		maxY = minY + 1
+		minY = minY - 1
+	}
+
+	plotLeft := float32(chartMargin * 3)
+	plotWidth := size.Width - plotLeft - chartMargin
+	plotHeight := size.Height - chartMargin*2
+
+	position := func(p chartPoint) fyne.Position {
+		x := plotLeft + float32((p.X-minX)/(maxX-minX))*plotWidth
+		y := chartMargin + plotHeight - float32((p.Value-minY)/(maxY-minY))*plotHeight
+		return fyne.NewPos(x, y)
+	}
+
+	var objects []fyne.CanvasObject
+
+	// Axes
+	xAxis := canvas.NewLine(theme.DisabledColor())
+	xAxis.Position1 = fyne.NewPos(plotLeft, chartMargin+plotHeight)
+	xAxis.Position2 = fyne.NewPos(plotLeft+plotWidth, chartMargin+plotHeight)
+	yAxis := canvas.NewLine(theme.DisabledColor())
+	yAxis.Position1 = fyne.NewPos(plotLeft, chartMargin)
+	yAxis.Position2 = fyne.NewPos(plotLeft, chartMargin+plotHeight)
+	objects = append(objects, xAxis, yAxis)
+
+	objects = append(objects,
+		chartText(fmt.Sprintf(valueFormat, maxY), fyne.NewPos(0, chartMargin-6)),
+		chartText(fmt.Sprintf(valueFormat, minY), fyne.NewPos(0, chartMargin+plotHeight-6)))
+
+	// Lines between consecutive points, and a dot on each point
+	for i, p := range points {
+		pos := position(p)
+		if i > 0 {
+			line := canvas.NewLine(theme.PrimaryColor())
+			line.StrokeWidth = 2
+			line.Position1 = position(points[i-1])
+			line.Position2 = pos
+			objects = append(objects, line)
+		}
+
+		dot := canvas.NewCircle(theme.PrimaryColor())
+		dot.Position1 = fyne.NewPos(pos.X-3, pos.Y-3)
+		dot.Position2 = fyne.NewPos(pos.X+3, pos.Y+3)
+		objects = append(objects, dot)
+	}
+
+	objects = append(objects, chartText(points[0].Label, fyne.NewPos(plotLeft, chartMargin+plotHeight+4)))
+	if len(points) > 1 {
+		last := chartText(points[len(points)-1].Label, fyne.NewPos(0, chartMargin+plotHeight+4))
+		last.Move(fyne.NewPos(plotLeft+plotWidth-last.MinSize().Width, last.Position().Y))
+		objects = append(objects, last)
+	}
+
+	return container.NewMax(background, container.NewWithoutLayout(objects...))
+}
+
+// chartText is a small label placed at pos. Objects in a chart have no
+// layout, so the text is sized explicitly.
+func chartText(text string, pos fyne.Position) *canvas.Text {
+	label := canvas.NewText(text, theme.ForegroundColor())
+	label.TextSize = 10
+	label.Resize(label.MinSize())
+	label.Move(pos)
+	return label
+}
+
+// chartBar is one bar of a bar chart
+type chartBar struct {
+	Label string
+	Value float64
+}
+
+// newBarChart draws one horizontal bar per value, longest for the largest,
+// with the label on the left and the value formatted with valueFormat at the
+// end of the bar. Negative values are drawn as empty bars.
+func newBarChart(bars []chartBar, width float32, valueFormat string) fyne.CanvasObject {
+	const barHeight, barGap, labelWidth, valueWidth = 16, 6, 150, 70
+
+	height := float32(len(bars))*(barHeight+barGap) + barGap
+	background := canvas.NewRectangle(color.Transparent)
+	background.SetMinSize(fyne.NewSize(width, height))
+
+	if len(bars) == 0 {
+		background.SetMinSize(fyne.NewSize(width, barHeight*2))
+		return container.NewMax(background, canvas.NewText("No data", theme.ForegroundColor()))
+	}
+
+	maxValue := 0.0
+	for _, bar := range bars {
+		maxValue = math.Max(maxValue, bar.Value)
+	}
+	if maxValue == 0 {
+		maxValue = 1
+	}
+
+	plotWidth := width - labelWidth - valueWidth
+	var objects []fyne.CanvasObject
+	for i, bar := range bars {
+		y := barGap + float32(i)*(barHeight+barGap)
+
+		label := chartText(bar.Label, fyne.NewPos(0, y+2))
+		if label.MinSize().Width > labelWidth-6 {
+			// Shorten long names to fit beside the bars
+			runes := []rune(bar.Label)
+			for len(runes) > 1 && label.MinSize().Width > labelWidth-6 {
+				runes = runes[:len(runes)-1]
+				label.Text = string(runes) + "…"
+				label.Resize(label.MinSize())
+			}
+		}
+
+		length := float32(math.Max(0, bar.Value)/maxValue) * plotWidth
+		rect := canvas.NewRectangle(theme.PrimaryColor())
+		rect.Move(fyne.NewPos(labelWidth, y))
+		rect.Resize(fyne.NewSize(length, barHeight))
+
+		value := chartText(fmt.Sprintf(valueFormat, bar.Value), fyne.NewPos(labelWidth+length+4, y+2))
+		objects = append(objects, label, rect, value)
+	}
+
+	return container.NewMax(background, container.NewWithoutLayout(objects...))
+}
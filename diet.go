@@ -0,0 +1,307 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dietTargets are the daily amounts a solved diet has to meet. Zero
+// nutrient values are left unconstrained.
+type dietTargets struct {
+	MinCalories  float64
+	MaxCalories  float64
+	MinNutrients Nutrients
+	MaxNutrients Nutrients
+	MaxGrams     float64 // cap on any one food, 0 for none
+}
+
+// foodLimit bounds how much of one food a diet may use each day
+type foodLimit struct {
+	Excluded bool
+	MinGrams float64
+	MaxGrams float64 // 0 uses the diet's MaxGrams
+}
+
+type dietItem struct {
+	Food     Food
+	Grams    float64
+	Calories float64
+	Cost     float64
+	Nutrients
+}
+
+type dietPlan struct {
+	Items    []dietItem
+	Calories float64
+	Cost     float64
+	Nutrients
+}
+
+// solveDiet finds the cheapest day of eating from the food database that
+// meets targets. Foods without a price or pack size are left out, since
+// they would look free.
+func solveDiet(targets dietTargets, limits map[int]foodLimit) (dietPlan, error) {
+	var candidates []Food
+	for _, food := range foods {
+		if food.Price <= 0 || food.Quantity <= 0 || limits[food.ID].Excluded {
+			continue
+		}
+		candidates = append(candidates, food)
+	}
+	if len(candidates) == 0 {
+		return dietPlan{}, fmt.Errorf("no priced foods to choose from")
+	}
+
+	// Each variable is hundreds of grams of one food, which keeps the
+	// coefficients close in size
+	perHundred := func(food Food) float64 {
+		return 100 / float64(food.Quantity)
+	}
+
+	objective := make([]float64, len(candidates))
+	calories := make([]float64, len(candidates))
+	for i, food := range candidates {
+		objective[i] = food.Price * perHundred(food)
+		calories[i] = food.Calories * perHundred(food)
+	}
+
+	var constraints []lpConstraint
+	constraints = append(constraints, lpConstraint{coeffs: calories, relation: atLeast, rhs: targets.MinCalories})
+	if targets.MaxCalories > 0 {
+		constraints = append(constraints, lpConstraint{coeffs: calories, relation: atMost, rhs: targets.MaxCalories})
+	}
+
+	for _, kind := range nutrientKinds {
+		minimum := *kind.value(&targets.MinNutrients)
+		maximum := *kind.value(&targets.MaxNutrients)
+		if minimum == 0 && maximum == 0 {
+			continue
+		}
+
+		coeffs := make([]float64, len(candidates))
+		for i, food := range candidates {
+			coeffs[i] = *kind.value(&food.Nutrients) * perHundred(food)
+		}
+		if minimum > 0 {
+			constraints = append(constraints, lpConstraint{coeffs: coeffs, relation: atLeast, rhs: minimum})
+		}
+		if maximum > 0 {
+			constraints = append(constraints, lpConstraint{coeffs: coeffs, relation: atMost, rhs: maximum})
+		}
+	}
+
+	for i, food := range candidates {
+		limit := limits[food.ID]
+		maxGrams := limit.MaxGrams
+		if maxGrams == 0 {
+			maxGrams = targets.MaxGrams
+		}
+
+		single := func() []float64 {
+			coeffs := make([]float64, len(candidates))
+			coeffs[i] = 1
+			return coeffs
+		}
+		if limit.MinGrams > 0 {
+			constraints = append(constraints, lpConstraint{coeffs: single(), relation: atLeast, rhs: limit.MinGrams / 100})
+		}
+		if maxGrams > 0 {
+			constraints = append(constraints, lpConstraint{coeffs: single(), relation: atMost, rhs: maxGrams / 100})
+		}
+	}
+
+	amounts, err := minimize(objective, constraints)
+	if err == errInfeasible {
+		return dietPlan{}, fmt.Errorf("no combination of foods meets these targets. Try wider limits or more foods")
+	}
+	if err != nil {
+		return dietPlan{}, err
+	}
+
+	var plan dietPlan
+	for i, food := range candidates {
+		grams := amounts[i] * 100
+		if grams < 0.5 {
+			continue
+		}
+		ratio := grams / float64(food.Quantity)
+		item := dietItem{
+			Food:      food,
+			Grams:     grams,
+			Calories:  food.Calories * ratio,
+			Cost:      food.Price * ratio,
+			Nutrients: food.Nutrients.Scale(ratio),
+		}
+		plan.Items = append(plan.Items, item)
+		plan.Calories += item.Calories
+		plan.Cost += item.Cost
+		plan.Nutrients = plan.Nutrients.Add(item.Nutrients)
+	}
+
+	sort.SliceStable(plan.Items, func(i, j int) bool {
+		return plan.Items[i].Cost > plan.Items[j].Cost
+	})
+	return plan, nil
+}
+
+func (plan dietPlan) Report() string {
+	var report strings.Builder
+	for _, item := range plan.Items {
+		report.WriteString(fmt.Sprintf("%s: %.0fg - %.0f cal, $%.2f\n", item.Food.Name, item.Grams, item.Calories, item.Cost))
+	}
+	report.WriteString(fmt.Sprintf("\nTotal: %.0f cal for $%.2f a day\n%s", plan.Calories, plan.Cost, plan.Nutrients.Summary()))
+	return report.String()
+}
+
+func showDietSolverWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Cheapest Diet")
+
+	// Default to today's calorie goal when there is one
+	minCalories := 2000.0
+	if goal, ok := goalForDate(time.Now().Format("2006-01-02")); ok && goal.Calories > 0 {
+		minCalories = goal.Calories
+	}
+
+	minCaloriesEntry := widget.NewEntry()
+	minCaloriesEntry.SetText(strconv.FormatFloat(minCalories, 'f', 0, 64))
+	maxCaloriesEntry := widget.NewEntry()
+	maxCaloriesEntry.SetText(strconv.FormatFloat(math.Round(minCalories*1.05), 'f', 0, 64))
+	maxGramsEntry := widget.NewEntry()
+	maxGramsEntry.SetText("500")
+
+	minNutrients := newNutrientInputs()
+	maxNutrients := newNutrientInputs()
+
+	// One row per food: use it or not, and optional gram limits
+	type limitRow struct {
+		food     Food
+		use      *widget.Check
+		minEntry *widget.Entry
+		maxEntry *widget.Entry
+	}
+	var rows []limitRow
+	limitsBox := container.NewVBox()
+	for _, food := range foods {
+		row := limitRow{
+			food:     food,
+			use:      widget.NewCheck(food.Name, nil),
+			minEntry: widget.NewEntry(),
+			maxEntry: widget.NewEntry(),
+		}
+		row.use.SetChecked(food.Price > 0)
+		if food.Price <= 0 {
+			row.use.Disable()
+			row.use.SetText(food.Name + " (no price)")
+		}
+		row.minEntry.SetPlaceHolder("Min g")
+		row.maxEntry.SetPlaceHolder("Max g")
+		rows = append(rows, row)
+		limitsBox.Add(container.NewGridWithColumns(3, row.use, row.minEntry, row.maxEntry))
+	}
+
+	resultLabel := widget.NewLabel("")
+
+	parseLimit := func(text, what string) (float64, error) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return 0, nil
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("Invalid %s. Please enter a number", what)
+		}
+		return value, nil
+	}
+
+	solveBtn := widget.NewButton("Find Cheapest Diet", func() {
+		var targets dietTargets
+		var err error
+
+		if targets.MinCalories, err = parseLimit(minCaloriesEntry.Text, "minimum calories"); err != nil {
+			resultLabel.SetText(err.Error())
+			return
+		}
+		if targets.MaxCalories, err = parseLimit(maxCaloriesEntry.Text, "maximum calories"); err != nil {
+			resultLabel.SetText(err.Error())
+			return
+		}
+		if targets.MaxCalories > 0 && targets.MaxCalories < targets.MinCalories {
+			resultLabel.SetText("Maximum calories must be at least the minimum")
+			return
+		}
+		if targets.MaxGrams, err = parseLimit(maxGramsEntry.Text, "maximum grams"); err != nil {
+			resultLabel.SetText(err.Error())
+			return
+		}
+		if err := minNutrients.Parse(&targets.MinNutrients); err != nil {
+			resultLabel.SetText(err.Error())
+			return
+		}
+		if err := maxNutrients.Parse(&targets.MaxNutrients); err != nil {
+			resultLabel.SetText(err.Error())
+			return
+		}
+
+		limits := make(map[int]foodLimit)
+		for _, row := range rows {
+			limit := foodLimit{Excluded: !row.use.Checked}
+			if limit.MinGrams, err = parseLimit(row.minEntry.Text, "minimum for "+row.food.Name); err != nil {
+				resultLabel.SetText(err.Error())
+				return
+			}
+			if limit.MaxGrams, err = parseLimit(row.maxEntry.Text, "maximum for "+row.food.Name); err != nil {
+				resultLabel.SetText(err.Error())
+				return
+			}
+			if limit.MaxGrams > 0 && limit.MaxGrams < limit.MinGrams {
+				resultLabel.SetText(fmt.Sprintf("Maximum for %s must be at least the minimum", row.food.Name))
+				return
+			}
+			limits[row.food.ID] = limit
+		}
+
+		plan, err := solveDiet(targets, limits)
+		if err != nil {
+			resultLabel.SetText(err.Error())
+			return
+		}
+		resultLabel.SetText(plan.Report())
+	})
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	targetsForm := widget.NewForm(
+		widget.NewFormItem("Calories at least", minCaloriesEntry),
+		widget.NewFormItem("Calories at most", maxCaloriesEntry),
+		widget.NewFormItem("Max grams of any food", maxGramsEntry),
+		widget.NewFormItem("Nutrients at least", minNutrients.Container()),
+		widget.NewFormItem("Nutrients at most", maxNutrients.Container()),
+	)
+
+	limitsScroll := container.NewVScroll(limitsBox)
+	limitsScroll.SetMinSize(fyne.NewSize(500, 200))
+
+	content := container.NewVBox(
+		widget.NewLabel("Daily Targets"),
+		targetsForm,
+		widget.NewLabel("Foods to use, with optional daily limits"),
+		limitsScroll,
+		solveBtn,
+		resultLabel,
+		backBtn,
+	)
+
+	window.SetContent(container.NewVScroll(content))
+	window.Resize(fyne.NewSize(560, 800))
+	window.Show()
+	return window
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const goalFile = "goals_data.json"
+
+// Goal is a daily calorie target, and optionally a food spending cap, that
+// applies from EffectiveFrom onwards. Weekdays limits it to certain days of
+// the week; an empty list means every day.
+type Goal struct {
+	ID            int            `json:"id"`
+	EffectiveFrom string         `json:"effective_from"` // YYYY-MM-DD
+	Weekdays      []time.Weekday `json:"weekdays,omitempty"`
+	Calories      float64        `json:"calories"`
+	Budget        float64        `json:"budget,omitempty"` // 0 means no cap
+}
+
+var goals []Goal
+
+// Save and load functions for goals
+func saveGoalsToFile() error {
+	file, err := os.Create(goalFile)
+	if err != nil {
+		return fmt.Errorf("error creating goal file: %v", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(goals); err != nil {
+		return fmt.Errorf("error encoding goal data: %v", err)
+	}
+	return nil
+}
+
+func loadGoalsFromFile() error {
+	file, err := os.Open(goalFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			goals = make([]Goal, 0)
+			return nil
+		}
+		return fmt.Errorf("error opening goal file: %v", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	if err := decoder.Decode(&goals); err != nil {
+		return fmt.Errorf("error decoding goal data: %v", err)
+	}
+	return nil
+}
+
+func (g Goal) appliesOn(day time.Weekday) bool {
+	if len(g.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range g.Weekdays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (g Goal) daysLabel() string {
+	if len(g.Weekdays) == 0 {
+		return "Every day"
+	}
+	names := make([]string, 0, len(g.Weekdays))
+	for _, d := range g.Weekdays {
+		names = append(names, d.String()[:3])
+	}
+	return strings.Join(names, ", ")
+}
+
+// goalForDate finds the goal that was in force on a date (YYYY-MM-DD). The
+// most recently effective goal for that weekday wins, and on the same
+// effective date a weekday goal beats an every-day one.
+func goalForDate(date string) (Goal, bool) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return Goal{}, false
+	}
+
+	var best Goal
+	found := false
+	for _, g := range goals {
+		if g.EffectiveFrom > date || !g.appliesOn(day.Weekday()) {
+			continue
+		}
+		if !found ||
+			g.EffectiveFrom > best.EffectiveFrom ||
+			(g.EffectiveFrom == best.EffectiveFrom && len(g.Weekdays) > 0 && len(best.Weekdays) == 0) {
+			best = g
+			found = true
+		}
+	}
+	return best, found
+}
+
+// goalMet reports whether a day's totals stayed within its goal
+func goalMet(g Goal, calories, cost float64) bool {
+	if calories > g.Calories {
+		return false
+	}
+	return g.Budget == 0 || cost <= g.Budget
+}
+
+func showGoalsWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Daily Goals")
+
+	sortGoals := func() {
+		sort.SliceStable(goals, func(i, j int) bool {
+			return goals[i].EffectiveFrom > goals[j].EffectiveFrom
+		})
+	}
+	sortGoals()
+
+	selected := -1
+
+	goalList := widget.NewList(
+		func() int { return len(goals) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			g := goals[id]
+			text := fmt.Sprintf("From %s, %s: %.0f cal", g.EffectiveFrom, g.daysLabel(), g.Calories)
+			if g.Budget > 0 {
+				text += fmt.Sprintf(", $%.2f", g.Budget)
+			}
+			item.(*widget.Label).SetText(text)
+		},
+	)
+	goalList.OnSelected = func(id widget.ListItemID) {
+		selected = id
+	}
+
+	effectiveEntry := widget.NewEntry()
+	effectiveEntry.SetText(time.Now().Format("2006-01-02"))
+	calendarBtn := widget.NewButton("📅", func() {
+		initial, err := time.Parse("2006-01-02", effectiveEntry.Text)
+		if err != nil {
+			initial = time.Now()
+		}
+		showDatePicker(window, initial, func(picked time.Time) {
+			effectiveEntry.SetText(picked.Format("2006-01-02"))
+		})
+	})
+
+	caloriesEntry := widget.NewEntry()
+	caloriesEntry.SetPlaceHolder("Daily calories")
+
+	budgetEntry := widget.NewEntry()
+	budgetEntry.SetPlaceHolder("Daily food budget $ (optional)")
+
+	dayNames := make([]string, 0, 7)
+	for d := time.Monday; d <= time.Saturday; d++ {
+		dayNames = append(dayNames, d.String())
+	}
+	dayNames = append(dayNames, time.Sunday.String())
+	daysCheck := widget.NewCheckGroup(dayNames, nil)
+	daysCheck.Horizontal = true
+
+	statusLabel := widget.NewLabel("Leave all days unticked for a goal that applies every day")
+
+	addBtn := widget.NewButton("Add Goal", func() {
+		var g Goal
+
+		effective, err := time.Parse("2006-01-02", strings.TrimSpace(effectiveEntry.Text))
+		if err != nil {
+			statusLabel.SetText("Please enter the effective date as YYYY-MM-DD")
+			return
+		}
+		g.EffectiveFrom = effective.Format("2006-01-02")
+
+		calories, err := strconv.ParseFloat(caloriesEntry.Text, 64)
+		if err != nil || calories <= 0 {
+			statusLabel.SetText("Invalid calories. Please enter a number")
+			return
+		}
+		g.Calories = calories
+
+		if budgetEntry.Text != "" {
+			budget, err := validateCurrency(budgetEntry.Text)
+			if err != nil || budget < 0 {
+				statusLabel.SetText("Invalid budget. Please enter an amount")
+				return
+			}
+			g.Budget = budget
+		}
+
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			for _, name := range daysCheck.Selected {
+				if name == d.String() {
+					g.Weekdays = append(g.Weekdays, d)
+				}
+			}
+		}
+
+		// Set ID based on existing goals
+		maxID := 0
+		for _, existing := range goals {
+			if existing.ID > maxID {
+				maxID = existing.ID
+			}
+		}
+		g.ID = maxID + 1
+
+		goals = append(goals, g)
+		sortGoals()
+		if err := saveGoalsToFile(); err != nil {
+			statusLabel.SetText("Error saving goal: " + err.Error())
+			return
+		}
+		notifyDiaryChanged()
+
+		goalList.UnselectAll()
+		selected = -1
+		goalList.Refresh()
+		statusLabel.SetText(fmt.Sprintf("Added goal of %.0f cal from %s", g.Calories, g.EffectiveFrom))
+		caloriesEntry.SetText("")
+		budgetEntry.SetText("")
+		daysCheck.SetSelected(nil)
+	})
+
+	removeBtn := widget.NewButton("Remove Selected", func() {
+		if selected < 0 || selected >= len(goals) {
+			statusLabel.SetText("Please select a goal")
+			return
+		}
+		dialog.ShowConfirm("Remove Goal",
+			"Past days will be judged against whichever goal applied before it. Remove it?",
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				goals = append(goals[:selected], goals[selected+1:]...)
+				if err := saveGoalsToFile(); err != nil {
+					statusLabel.SetText("Error saving goals: " + err.Error())
+					return
+				}
+				notifyDiaryChanged()
+				goalList.UnselectAll()
+				selected = -1
+				goalList.Refresh()
+				statusLabel.SetText("Goal removed")
+			}, window)
+	})
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	listScroll := container.NewScroll(goalList)
+	listScroll.SetMinSize(fyne.NewSize(450, 200))
+
+	form := widget.NewForm(
+		widget.NewFormItem("Effective from", container.NewBorder(nil, nil, nil, calendarBtn, effectiveEntry)),
+		widget.NewFormItem("Calories", caloriesEntry),
+		widget.NewFormItem("Budget", budgetEntry),
+		widget.NewFormItem("Days", daysCheck),
+	)
+
+	content := container.NewVBox(
+		widget.NewLabel("Daily Goals"),
+		listScroll,
+		removeBtn,
+		widget.NewSeparator(),
+		form,
+		addBtn,
+		statusLabel,
+		backBtn,
+	)
+
+	window.SetContent(content)
+	window.Resize(fyne.NewSize(500, 600))
+	window.Show()
+	return window
+}
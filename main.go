@@ -1,11 +1,11 @@
 package main
 
-//TODO: Add way to make recipes out of fodd you have added
 //TODO: imporve money tracking so you can easily see expenses and upcoming as well as breakdowns of what you can spend etc... Maybe allow user to add a budget strategy like 40-60 or somethibg
 
 import (
 	"bufio"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -18,6 +18,7 @@ import (
 	"log"
 	"math"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -37,25 +38,214 @@ type Food struct {
 	Quantity     int     `json:"quantity"` // in grams
 	CalPerDollar float64 `json:"cal_per_dollar"`
 	CalPer100g   float64 `json:"cal_per_100g"`
+	RecipeID     int     `json:"recipe_id,omitempty"` // set when the food is built from a recipe
+	Nutrients
+	PriceHistory []PricePoint  `json:"price_history,omitempty"`
+	Favourite    bool          `json:"favourite,omitempty"`
+	Barcode      string        `json:"barcode,omitempty"` // EAN/UPC
+	Units        []PortionUnit `json:"units,omitempty"`
+	Density      float64       `json:"density,omitempty"` // g/ml, water when unset
+	Category     string        `json:"category,omitempty"`
+	Tags         []string      `json:"tags,omitempty"`
 }
 
 type DiaryEntry struct {
-	ID       int     `json:"id"`
-	Date     string  `json:"date"`
-	FoodID   int     `json:"food_id"`
-	FoodName string  `json:"food_name"`
-	Quantity int     `json:"quantity"` // in grams
-	Calories float64 `json:"calories"`
-	Cost     float64 `json:"cost"`
+	ID       int      `json:"id"`
+	Date     string   `json:"date"`
+	FoodID   int      `json:"food_id"`
+	FoodName string   `json:"food_name"`
+	Quantity int      `json:"quantity"` // in grams
+	Calories float64  `json:"calories"`
+	Cost     float64  `json:"cost"`
+	Meal     MealSlot `json:"meal,omitempty"`
+	Time     string   `json:"time,omitempty"`   // HH:MM, optional
+	Amount   float64  `json:"amount,omitempty"` // in Unit, when not logged in grams
+	Unit     string   `json:"unit,omitempty"`
+	Planned  bool     `json:"planned,omitempty"`   // a meal plan item rather than food eaten
+	LoggedID int      `json:"logged_id,omitempty"` // diary entry a planned item became
+	Nutrients
+}
+
+type MealSlot string
+
+const (
+	Breakfast MealSlot = "breakfast"
+	Lunch     MealSlot = "lunch"
+	Dinner    MealSlot = "dinner"
+	Snack     MealSlot = "snack"
+)
+
+// mealSlots is the display order; entries logged before meals existed have
+// an empty slot and are shown last
+var mealSlots = []MealSlot{Breakfast, Lunch, Dinner, Snack, ""}
+
+func (m MealSlot) Label() string {
+	switch m {
+	case Breakfast:
+		return "Breakfast"
+	case Lunch:
+		return "Lunch"
+	case Dinner:
+		return "Dinner"
+	case Snack:
+		return "Snacks"
+	default:
+		return "Unassigned"
+	}
+}
+
+func mealSlotFromLabel(label string) MealSlot {
+	for _, m := range mealSlots {
+		if m.Label() == label {
+			return m
+		}
+	}
+	return ""
+}
+
+// mealForTime guesses the meal slot from the time of day
+func mealForTime(t time.Time) MealSlot {
+	switch hour := t.Hour(); {
+	case hour >= 5 && hour < 11:
+		return Breakfast
+	case hour >= 11 && hour < 15:
+		return Lunch
+	case hour >= 17 && hour < 21:
+		return Dinner
+	default:
+		return Snack
+	}
+}
+
+// Nutrients are totals for the same amount as the Calories they sit next to
+type Nutrients struct {
+	Protein float64 `json:"protein,omitempty"` // in grams
+	Carbs   float64 `json:"carbs,omitempty"`   // in grams
+	Fat     float64 `json:"fat,omitempty"`     // in grams
+	Fibre   float64 `json:"fibre,omitempty"`   // in grams
+	Sugar   float64 `json:"sugar,omitempty"`   // in grams
+	Sodium  float64 `json:"sodium,omitempty"`  // in milligrams
+}
+
+func (n Nutrients) Scale(ratio float64) Nutrients {
+	return Nutrients{
+		Protein: n.Protein * ratio,
+		Carbs:   n.Carbs * ratio,
+		Fat:     n.Fat * ratio,
+		Fibre:   n.Fibre * ratio,
+		Sugar:   n.Sugar * ratio,
+		Sodium:  n.Sodium * ratio,
+	}
+}
+
+func (n Nutrients) Add(other Nutrients) Nutrients {
+	return Nutrients{
+		Protein: n.Protein + other.Protein,
+		Carbs:   n.Carbs + other.Carbs,
+		Fat:     n.Fat + other.Fat,
+		Fibre:   n.Fibre + other.Fibre,
+		Sugar:   n.Sugar + other.Sugar,
+		Sodium:  n.Sodium + other.Sodium,
+	}
+}
+
+// Summary is a method rather than String so it is not promoted onto the
+// structs that embed Nutrients
+func (n Nutrients) Summary() string {
+	return fmt.Sprintf("Protein: %.1fg, Carbs: %.1fg, Fat: %.1fg\nFibre: %.1fg, Sugar: %.1fg, Sodium: %.0fmg",
+		n.Protein, n.Carbs, n.Fat, n.Fibre, n.Sugar, n.Sodium)
+}
+
+// nutrientKind describes one field of Nutrients, for code that works through
+// every nutrient in turn
+type nutrientKind struct {
+	name  string
+	label string
+	unit  string
+	value func(*Nutrients) *float64
+}
+
+var nutrientKinds = []nutrientKind{
+	{"protein", "Protein", "grams", func(n *Nutrients) *float64 { return &n.Protein }},
+	{"carbs", "Carbs", "grams", func(n *Nutrients) *float64 { return &n.Carbs }},
+	{"fat", "Fat", "grams", func(n *Nutrients) *float64 { return &n.Fat }},
+	{"fibre", "Fibre", "grams", func(n *Nutrients) *float64 { return &n.Fibre }},
+	{"sugar", "Sugar", "grams", func(n *Nutrients) *float64 { return &n.Sugar }},
+	{"sodium", "Sodium", "milligrams", func(n *Nutrients) *float64 { return &n.Sodium }},
+}
+
+// nutrientInputs are the optional nutrient fields shared by the food forms
+type nutrientInputs struct {
+	fields []nutrientField
+}
+
+type nutrientField struct {
+	name  string
+	entry *widget.Entry
+	value func(*Nutrients) *float64
+}
+
+func newNutrientInputs() *nutrientInputs {
+	in := &nutrientInputs{}
+	for _, kind := range nutrientKinds {
+		entry := widget.NewEntry()
+		entry.SetPlaceHolder(fmt.Sprintf("%s (%s, optional)", kind.label, kind.unit))
+		in.fields = append(in.fields, nutrientField{name: kind.name, entry: entry, value: kind.value})
+	}
+	return in
+}
+
+func (in *nutrientInputs) Container() *fyne.Container {
+	box := container.NewVBox()
+	for _, field := range in.fields {
+		box.Add(field.entry)
+	}
+	return box
+}
+
+// Parse fills n from the inputs, treating blank fields as zero
+func (in *nutrientInputs) Parse(n *Nutrients) error {
+	var parsed Nutrients
+	for _, field := range in.fields {
+		if field.entry.Text == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(field.entry.Text, 64)
+		if err != nil || value < 0 {
+			return fmt.Errorf("Invalid %s. Please enter a number", field.name)
+		}
+		*field.value(&parsed) = value
+	}
+	*n = parsed
+	return nil
+}
+
+func (in *nutrientInputs) SetNutrients(n Nutrients) {
+	for _, field := range in.fields {
+		value := *field.value(&n)
+		if value == 0 {
+			field.entry.SetText("")
+			continue
+		}
+		field.entry.SetText(strconv.FormatFloat(value, 'f', -1, 64))
+	}
 }
 
 type DailyDiary struct {
 	Entries []DiaryEntry `json:"entries"`
+
+	// LastEntryID is the highest ID ever handed out, so IDs of deleted
+	// entries are not reused by new ones
+	LastEntryID int `json:"last_entry_id,omitempty"`
 }
 
 var (
 	foods      []Food
 	dailyDiary DailyDiary
+
+	// diaryListeners let open windows refresh when the diary changes
+	diaryListeners      = make(map[int]func())
+	nextDiaryListenerID int
 )
 
 // Save and load functions for the food database
@@ -108,6 +298,23 @@ func saveDiaryToFile() error {
 	return nil
 }
 
+// addDiaryListener registers a refresh callback and returns a function that
+// removes it again
+func addDiaryListener(listener func()) func() {
+	nextDiaryListenerID++
+	id := nextDiaryListenerID
+	diaryListeners[id] = listener
+	return func() {
+		delete(diaryListeners, id)
+	}
+}
+
+func notifyDiaryChanged() {
+	for _, listener := range diaryListeners {
+		listener()
+	}
+}
+
 func loadDiaryFromFile() error {
 	file, err := os.Open(diaryFile)
 	if err != nil {
@@ -126,6 +333,184 @@ func loadDiaryFromFile() error {
 	return nil
 }
 
+// updateFoodStats recalculates the derived per-dollar and per-100g values
+func updateFoodStats(food *Food) {
+	food.CalPerDollar = 0
+	if food.Price > 0 {
+		food.CalPerDollar = food.Calories / food.Price
+	}
+	food.CalPer100g = 0
+	if food.Quantity > 0 {
+		food.CalPer100g = (food.Calories / float64(food.Quantity)) * 100
+	}
+}
+
+func getFoodByID(id int) *Food {
+	for i := range foods {
+		if foods[i].ID == id {
+			return &foods[i]
+		}
+	}
+	return nil
+}
+
+// nextFoodID also skips IDs still used by diary entries, planned meals,
+// purchases and recipes, so a new food doesn't take over the history of a
+// deleted one
+func nextFoodID() int {
+	maxID := 0
+	for _, f := range foods {
+		maxID = maxInt(maxID, f.ID)
+	}
+	for _, entry := range dailyDiary.Entries {
+		maxID = maxInt(maxID, entry.FoodID)
+	}
+	for _, entry := range mealPlan {
+		maxID = maxInt(maxID, entry.FoodID)
+	}
+	for _, p := range purchases {
+		maxID = maxInt(maxID, p.FoodID)
+	}
+	for _, r := range recipes {
+		maxID = maxInt(maxID, r.FoodID)
+		for _, ing := range r.Ingredients {
+			maxID = maxInt(maxID, ing.FoodID)
+		}
+	}
+	return maxID + 1
+}
+
+func getDiaryEntryIndex(id int) int {
+	for i, entry := range dailyDiary.Entries {
+		if entry.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextDiaryEntryID never reuses an ID, even after entries are deleted. It
+// counts on from the diary's high-water mark, which is saved with the
+// entries; diaries saved before the mark existed start from their highest ID.
+func nextDiaryEntryID() int {
+	maxID := dailyDiary.LastEntryID
+	for _, entry := range dailyDiary.Entries {
+		if entry.ID > maxID {
+			maxID = entry.ID
+		}
+	}
+	dailyDiary.LastEntryID = maxID + 1
+	return dailyDiary.LastEntryID
+}
+
+// newDiaryEntry prices a portion of food using the price in effect on date.
+// The caller fills in the ID and meal.
+func newDiaryEntry(food Food, grams float64, date string) DiaryEntry {
+	var ratio float64
+	if food.Quantity > 0 {
+		ratio = grams / float64(food.Quantity)
+	}
+
+	return DiaryEntry{
+		Date:      date,
+		FoodID:    food.ID,
+		FoodName:  food.Name,
+		Quantity:  int(math.Round(grams)),
+		Calories:  food.Calories * ratio,
+		Cost:      food.priceOn(date) * ratio,
+		Nutrients: food.Nutrients.Scale(ratio),
+	}
+}
+
+// recomputeDiaryEntry reprices an existing entry against food, keeping its
+// ID, quantity, date and meal. Entries logged in a unit the food no longer
+// has fall back to their gram weight.
+func recomputeDiaryEntry(entry DiaryEntry, food Food) DiaryEntry {
+	updated, err := newDiaryEntryInUnit(food, entry.Amount, entry.Unit, entry.Date)
+	if entry.Unit == "" || err != nil {
+		updated = newDiaryEntry(food, float64(entry.Quantity), entry.Date)
+	}
+	updated.ID = entry.ID
+	updated.Meal = entry.Meal
+	updated.Time = entry.Time
+	return updated
+}
+
+// updateDiaryEntry replaces the stored entry with the same ID, recomputing
+// calories and cost from its food
+func updateDiaryEntry(updated DiaryEntry) error {
+	idx := getDiaryEntryIndex(updated.ID)
+	if idx < 0 {
+		return fmt.Errorf("diary entry %d not found", updated.ID)
+	}
+
+	food := getFoodByID(updated.FoodID)
+	if food == nil {
+		return fmt.Errorf("food %d no longer exists", updated.FoodID)
+	}
+
+	dailyDiary.Entries[idx] = recomputeDiaryEntry(updated, *food)
+	if err := saveDiaryToFile(); err != nil {
+		return err
+	}
+	notifyDiaryChanged()
+	return nil
+}
+
+func deleteDiaryEntry(id int) error {
+	idx := getDiaryEntryIndex(id)
+	if idx < 0 {
+		return fmt.Errorf("diary entry %d not found", id)
+	}
+
+	dailyDiary.Entries = append(dailyDiary.Entries[:idx], dailyDiary.Entries[idx+1:]...)
+	if err := saveDiaryToFile(); err != nil {
+		return err
+	}
+	notifyDiaryChanged()
+	return nil
+}
+
+// entriesFor returns the entries logged on date in meal
+func entriesFor(date string, meal MealSlot) []DiaryEntry {
+	var entries []DiaryEntry
+	for _, entry := range dailyDiary.Entries {
+		if entry.Date == date && entry.Meal == meal {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// copyDiaryEntries logs entries again on date as new entries, keeping their
+// amount, meal and time but using today's food prices. Entries whose food
+// has since been deleted are skipped. It returns how many were copied.
+func copyDiaryEntries(entries []DiaryEntry, date string) (int, error) {
+	today := time.Now().Format("2006-01-02")
+	copied := 0
+	for _, entry := range entries {
+		food := getFoodByID(entry.FoodID)
+		if food == nil {
+			continue
+		}
+		entry.Date = today
+		entry = recomputeDiaryEntry(entry, *food)
+		entry.Date = date
+		entry.ID = nextDiaryEntryID()
+		dailyDiary.Entries = append(dailyDiary.Entries, entry)
+		copied++
+	}
+	if copied == 0 {
+		return 0, nil
+	}
+
+	if err := saveDiaryToFile(); err != nil {
+		return 0, err
+	}
+	notifyDiaryChanged()
+	return copied, nil
+}
+
 func readInput(prompt string) string {
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Print(prompt)
@@ -134,24 +519,23 @@ func readInput(prompt string) string {
 }
 
 func searchFoods() {
-	query := readInput("Enter food name to search: ")
-	query = strings.ToLower(query)
+	query := readInput("Enter food name or #tag to search (or press Enter for recent foods): ")
 
-	found := false
-	for _, food := range foods {
-		if strings.Contains(strings.ToLower(food.Name), query) {
-			fmt.Printf("\nFound: %s\n", food.Name)
-			fmt.Printf("Price: $%.2f\n", food.Price)
-			fmt.Printf("Quantity: %dg\n", food.Quantity)
-			fmt.Printf("Calories: %.0f\n", food.Calories)
-			fmt.Printf("Calories per Dollar: %.0f\n", food.CalPerDollar)
-			fmt.Printf("Calories per 100g: %.0f\n", food.CalPer100g)
-			found = true
-		}
+	results := rankFoods(query)
+	if len(results) == 0 {
+		fmt.Println("No foods found matching your search.")
+		return
 	}
 
-	if !found {
-		fmt.Println("No foods found matching your search.")
+	for _, food := range results {
+		fmt.Printf("\nFound: %s %s\n", favouriteLabel(food), food.Name)
+		fmt.Printf("Price: $%.2f\n", food.Price)
+		fmt.Printf("Quantity: %dg\n", food.Quantity)
+		fmt.Printf("Calories: %.0f\n", food.Calories)
+		fmt.Printf("Calories per Dollar: %.0f\n", food.CalPerDollar)
+		fmt.Printf("Calories per 100g: %.0f\n", food.CalPer100g)
+		fmt.Println(food.Nutrients.Summary())
+		fmt.Println(tagsSummary(food))
 	}
 }
 
@@ -166,14 +550,9 @@ func viewStats() {
 	copy(sortedFoods, foods)
 
 	fmt.Println("\nFoods ordered by calories per dollar:")
-	// Simple bubble sort by CalPerDollar
-	for i := 0; i < len(sortedFoods)-1; i++ {
-		for j := 0; j < len(sortedFoods)-i-1; j++ {
-			if sortedFoods[j].CalPerDollar < sortedFoods[j+1].CalPerDollar {
-				sortedFoods[j], sortedFoods[j+1] = sortedFoods[j+1], sortedFoods[j]
-			}
-		}
-	}
+	sort.SliceStable(sortedFoods, func(i, j int) bool {
+		return sortedFoods[i].CalPerDollar > sortedFoods[j].CalPerDollar
+	})
 
 	for i, food := range sortedFoods {
 		fmt.Printf("%d. %s: %.0f calories/$\n",
@@ -192,6 +571,8 @@ func showSummary(myApp fyne.App) fyne.Window {
 	var (
 		totalCalories   float64
 		totalFoodCost   float64
+		totalNutrients  Nutrients
+		caloriesByMeal  = make(map[MealSlot]float64)
 		daysWithEntries int
 		caloriesByDay   = make(map[string]float64)
 		costByDay       = make(map[string]float64)
@@ -208,6 +589,8 @@ func showSummary(myApp fyne.App) fyne.Window {
 		if entryDate.After(thirtyDaysAgo) || entryDate.Equal(thirtyDaysAgo) {
 			totalCalories += entry.Calories
 			totalFoodCost += entry.Cost
+			totalNutrients = totalNutrients.Add(entry.Nutrients)
+			caloriesByMeal[entry.Meal] += entry.Calories
 
 			// Aggregate by day
 			caloriesByDay[entry.Date] += entry.Calories
@@ -232,6 +615,80 @@ func showSummary(myApp fyne.App) fyne.Window {
 		summaryText += fmt.Sprintf("Average daily calories: %.0f\n", avgDailyCalories)
 		summaryText += fmt.Sprintf("Average daily food cost: $%.2f\n", avgDailyFoodCost)
 		summaryText += fmt.Sprintf("Total food spending: $%.2f\n", totalFoodCost)
+		summaryText += fmt.Sprintf("\nAverage daily nutrients:\n%s\n",
+			totalNutrients.Scale(1/float64(daysWithEntries)).Summary())
+	}
+
+	// Days that stayed within the goal in force at the time
+	var daysWithGoal, daysGoalMet int
+	for date, calories := range caloriesByDay {
+		goal, ok := goalForDate(date)
+		if !ok {
+			continue
+		}
+		daysWithGoal++
+		if goalMet(goal, calories, costByDay[date]) {
+			daysGoalMet++
+		}
+	}
+	if daysWithGoal > 0 {
+		summaryText += fmt.Sprintf("Daily goal met: %d of %d days\n", daysGoalMet, daysWithGoal)
+	}
+
+	// Average water intake over the whole 30 days, so days with nothing
+	// logged count as nothing drunk
+	waterByDay := make(map[string]int)
+	for _, entry := range waterLog.Entries {
+		entryDate, err := time.Parse("2006-01-02", entry.Date)
+		if err == nil && !entryDate.Before(thirtyDaysAgo) {
+			waterByDay[entry.Date] += entry.Millilitres
+		}
+	}
+	if len(waterByDay) > 0 {
+		var totalWater, daysTargetMet int
+		for _, millilitres := range waterByDay {
+			totalWater += millilitres
+			if millilitres >= waterLog.Target {
+				daysTargetMet++
+			}
+		}
+		summaryText += fmt.Sprintf("Average daily water: %dml over 30 days (logged on %d, target %dml met on %d)\n",
+			totalWater/30, len(waterByDay), waterLog.Target, daysTargetMet)
+	}
+
+	// Average calorie split between meals
+	if totalCalories > 0 {
+		summaryText += "\nCalorie split by meal:\n"
+		for _, meal := range mealSlots {
+			if caloriesByMeal[meal] == 0 {
+				continue
+			}
+			summaryText += fmt.Sprintf("%s: %.0f%% (%.0f cal/day)\n",
+				meal.Label(),
+				caloriesByMeal[meal]/totalCalories*100,
+				caloriesByMeal[meal]/float64(daysWithEntries))
+		}
+	}
+
+	// Calories and cost by food category
+	var periodEntries []DiaryEntry
+	for _, entry := range dailyDiary.Entries {
+		entryDate, err := time.Parse("2006-01-02", entry.Date)
+		if err == nil && !entryDate.Before(thirtyDaysAgo) {
+			periodEntries = append(periodEntries, entry)
+		}
+	}
+	if categoryTotals := totalsByGroup(periodEntries, entryCategory); len(categoryTotals) > 0 && totalCalories > 0 {
+		summaryText += "\nBy category:\n"
+		for _, total := range categoryTotals {
+			summaryText += fmt.Sprintf("%s: %.0f cal (%.0f%%), $%.2f\n",
+				total.Group, total.Calories, total.Calories/totalCalories*100, total.Cost)
+		}
+	}
+
+	// Trend weight and the maintenance calories it implies
+	if len(weightLog.Entries) > 0 {
+		summaryText += "\n" + weightReport() + "\n"
 	}
 
 	// Calculate and add trends if enough data
@@ -285,23 +742,47 @@ func addFoodToDiary(myApp fyne.App) fyne.Window {
 	searchEntry := widget.NewEntry()
 	searchEntry.SetPlaceHolder("Search for food...")
 
-	// Create list to show search results
-	resultsList := widget.NewList(
-		func() int { return 0 }, // Will be updated when search changes
-		func() fyne.CanvasObject {
-			return container.NewHBox(
-				widget.NewLabel(""), // Food name
-				widget.NewLabel(""), // Price and quantity
-				widget.NewLabel(""), // Calories
-			)
-		},
-		func(id widget.ListItemID, item fyne.CanvasObject) {}, // Will be updated when search changes
-	)
-
-	// Create quantity entry (hidden initially)
+	// Create quantity entry and unit picker (hidden initially)
 	quantityEntry := widget.NewEntry()
-	quantityEntry.SetPlaceHolder("Enter quantity in grams")
-	quantityEntry.Hide()
+	quantityEntry.SetPlaceHolder("Enter amount")
+	unitSelect := widget.NewSelect([]string{unitGrams}, nil)
+	unitSelect.SetSelected(unitGrams)
+	quantityContainer := container.NewBorder(nil, nil, nil, unitSelect, quantityEntry)
+	quantityContainer.Hide()
+
+	// Create meal and time pickers (hidden initially)
+	mealOptions := make([]string, 0, len(mealSlots))
+	for _, meal := range mealSlots {
+		if meal != "" {
+			mealOptions = append(mealOptions, meal.Label())
+		}
+	}
+	mealSelect := widget.NewSelect(mealOptions, nil)
+	mealSelect.SetSelected(mealForTime(time.Now()).Label())
+	mealSelect.Hide()
+
+	timeEntry := widget.NewEntry()
+	timeEntry.SetPlaceHolder("Time of day HH:MM (optional)")
+	timeEntry.Hide()
+
+	// Create date entry, defaulting to today, with the same calendar picker
+	// as the diary (hidden initially)
+	dateEntry := widget.NewEntry()
+	dateEntry.SetPlaceHolder("YYYY-MM-DD")
+	dateEntry.SetText(time.Now().Format("2006-01-02"))
+
+	calendarBtn := widget.NewButton("📅", func() {
+		initial, err := time.Parse("2006-01-02", dateEntry.Text)
+		if err != nil {
+			initial = time.Now()
+		}
+		showDatePicker(window, initial, func(picked time.Time) {
+			dateEntry.SetText(picked.Format("2006-01-02"))
+		})
+	})
+
+	dateContainer := container.NewBorder(nil, nil, nil, calendarBtn, dateEntry)
+	dateContainer.Hide()
 
 	// Create add button (hidden initially)
 	addButton := widget.NewButton("Add to Diary", nil)
@@ -310,79 +791,94 @@ func addFoodToDiary(myApp fyne.App) fyne.Window {
 	// Create status label for feedback
 	statusLabel := widget.NewLabel("")
 
-	var selectedFood Food
-	var matchedFoods []Food
-
-	// Update search results as user types
-	searchEntry.OnChanged = func(searchText string) {
-		matchedFoods = nil
-		searchText = strings.ToLower(searchText)
-
-		if searchText == "" {
-			resultsList.Refresh()
+	// Shortcut to log one of yesterday's meals again today
+	repeatMealSelect := widget.NewSelect(mealOptions, nil)
+	repeatMealSelect.SetSelected(mealForTime(time.Now()).Label())
+	repeatBtn := widget.NewButton("Log Again Today", func() {
+		meal := mealSlotFromLabel(repeatMealSelect.Selected)
+		yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+		entries := entriesFor(yesterday, meal)
+		if len(entries) == 0 {
+			statusLabel.SetText(fmt.Sprintf("Nothing was logged for %s yesterday", strings.ToLower(meal.Label())))
 			return
 		}
 
-		// Search for matching foods
-		for _, food := range foods {
-			if strings.Contains(strings.ToLower(food.Name), searchText) {
-				matchedFoods = append(matchedFoods, food)
-			}
-		}
-
-		// Update list data
-		resultsList.Length = func() int {
-			return len(matchedFoods)
+		copied, err := copyDiaryEntries(entries, time.Now().Format("2006-01-02"))
+		if err != nil {
+			statusLabel.SetText("Error saving diary")
+			log.Printf("Warning: Failed to save diary: %v", err)
+			return
 		}
+		statusLabel.SetText(fmt.Sprintf("Logged %d items from yesterday's %s", copied, strings.ToLower(meal.Label())))
+	})
+	repeatContainer := container.NewBorder(nil, nil, widget.NewLabel("Same as yesterday's"), repeatBtn, repeatMealSelect)
 
-		resultsList.UpdateItem = func(id widget.ListItemID, item fyne.CanvasObject) {
-			food := matchedFoods[id]
-			box := item.(*fyne.Container)
-
-			// Update labels in the container
-			nameLabel := box.Objects[0].(*widget.Label)
-			priceLabel := box.Objects[1].(*widget.Label)
-			calLabel := box.Objects[2].(*widget.Label)
+	var selectedFood Food
+	var matchedFoods []Food
 
-			nameLabel.SetText(food.Name)
-			priceLabel.SetText(fmt.Sprintf("$%.2f/%dg", food.Price, food.Quantity))
-			calLabel.SetText(fmt.Sprintf("%.0f cal", food.Calories))
-		}
+	// Create list to show ranked search results, or recent foods when the
+	// search is empty
+	resultsList := newFoodResultsList(&matchedFoods, func() {
+		searchEntry.OnChanged(searchEntry.Text)
+	})
 
-		resultsList.OnSelected = func(id widget.ListItemID) {
-			selectedFood = matchedFoods[id]
-			quantityEntry.Show()
-			addButton.Show()
-			statusLabel.SetText("")
-		}
+	resultsList.OnSelected = func(id widget.ListItemID) {
+		selectedFood = matchedFoods[id]
+		unitSelect.Options = selectedFood.unitNames()
+		unitSelect.SetSelected(unitGrams)
+		quantityContainer.Show()
+		dateContainer.Show()
+		mealSelect.Show()
+		timeEntry.Show()
+		addButton.Show()
+		statusLabel.SetText("")
+	}
 
+	// Update search results as user types
+	searchEntry.OnChanged = func(searchText string) {
+		matchedFoods = rankFoods(searchText)
+		resultsList.UnselectAll()
 		resultsList.Refresh()
 	}
 
 	// Handle adding food to diary
 	addButton.OnTapped = func() {
-		quantityStr := quantityEntry.Text
-		quantity, err := strconv.Atoi(quantityStr)
-		if err != nil {
+		quantityStr := strings.TrimSpace(quantityEntry.Text)
+		quantity, err := strconv.ParseFloat(quantityStr, 64)
+		if err != nil || quantity <= 0 {
 			statusLabel.SetText("Please enter a valid quantity")
 			return
 		}
 
-		// Calculate proportional calories and cost
-		ratio := float64(quantity) / float64(selectedFood.Quantity)
-		calories := selectedFood.Calories * ratio
-		cost := selectedFood.Price * ratio
+		entryDate, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(dateEntry.Text), time.Local)
+		if err != nil {
+			statusLabel.SetText("Please enter the date as YYYY-MM-DD")
+			return
+		}
+		if entryDate.After(time.Now()) {
+			statusLabel.SetText("Cannot log food for a future date")
+			return
+		}
+
+		timeOfDay := strings.TrimSpace(timeEntry.Text)
+		if timeOfDay != "" {
+			parsed, err := time.Parse("15:04", timeOfDay)
+			if err != nil {
+				statusLabel.SetText("Please enter the time as HH:MM")
+				return
+			}
+			timeOfDay = parsed.Format("15:04")
+		}
 
-		// Create diary entry
-		entry := DiaryEntry{
-			ID:       len(dailyDiary.Entries) + 1,
-			Date:     time.Now().Format("2006-01-02"),
-			FoodID:   selectedFood.ID,
-			FoodName: selectedFood.Name,
-			Quantity: quantity,
-			Calories: calories,
-			Cost:     cost,
+		// Create diary entry with proportional calories and cost
+		entry, err := newDiaryEntryInUnit(selectedFood, quantity, unitSelect.Selected, entryDate.Format("2006-01-02"))
+		if err != nil {
+			statusLabel.SetText(err.Error())
+			return
 		}
+		entry.ID = nextDiaryEntryID()
+		entry.Meal = mealSlotFromLabel(mealSelect.Selected)
+		entry.Time = timeOfDay
 
 		// Add to diary and save
 		dailyDiary.Entries = append(dailyDiary.Entries, entry)
@@ -391,15 +887,20 @@ func addFoodToDiary(myApp fyne.App) fyne.Window {
 			log.Printf("Warning: Failed to save diary: %v", err)
 			return
 		}
+		notifyDiaryChanged()
 
 		// Show success message
-		statusLabel.SetText(fmt.Sprintf("Added %s: %dg (%.0f cal, $%.2f)",
-			selectedFood.Name, quantity, calories, cost))
+		statusLabel.SetText(fmt.Sprintf("Added %s to %s on %s: %s (%.0f cal, $%.2f)",
+			selectedFood.Name, entry.Meal.Label(), entry.Date, entry.amountLabel(), entry.Calories, entry.Cost))
 
 		// Reset fields
 		searchEntry.SetText("")
 		quantityEntry.SetText("")
-		quantityEntry.Hide()
+		timeEntry.SetText("")
+		quantityContainer.Hide()
+		dateContainer.Hide()
+		mealSelect.Hide()
+		timeEntry.Hide()
 		addButton.Hide()
 		selectedFood = Food{}
 	}
@@ -409,12 +910,20 @@ func addFoodToDiary(myApp fyne.App) fyne.Window {
 		window.Close()
 	})
 
+	// Start with favourites and recent foods
+	searchEntry.OnChanged("")
+
 	// Layout everything
 	content := container.NewVBox(
+		repeatContainer,
+		widget.NewSeparator(),
 		widget.NewLabel("Search Foods"),
 		searchEntry,
 		resultsList,
-		quantityEntry,
+		quantityContainer,
+		dateContainer,
+		mealSelect,
+		timeEntry,
 		addButton,
 		statusLabel,
 		cancelButton,
@@ -441,12 +950,56 @@ func showFoodWindow(myApp fyne.App) {
 		viewDiary(myApp)
 	})
 
-	searchFoodBtn := widget.NewButton("Search Foods", func() {
+	recipesBtn := widget.NewButton("Recipes", func() {
+		showRecipeWindow(myApp)
+	})
 
+	manageFoodsBtn := widget.NewButton("Manage Foods", func() {
+		showManageFoodsWindow(myApp)
 	})
 
-	viewStatsBtn := widget.NewButton("View Stats", func() {
+	goalsBtn := widget.NewButton("Daily Goals", func() {
+		showGoalsWindow(myApp)
+	})
+
+	priceHistoryBtn := widget.NewButton("Price History", func() {
+		showPriceHistoryWindow(myApp)
+	})
+
+	waterBtn := widget.NewButton("Water", func() {
+		showWaterWindow(myApp)
+	})
+
+	weightBtn := widget.NewButton("Body Weight", func() {
+		showWeightWindow(myApp)
+	})
+
+	planBtn := widget.NewButton("Meal Planner", func() {
+		showMealPlanWindow(myApp)
+	})
 
+	shoppingBtn := widget.NewButton("Shopping List", func() {
+		showShoppingListWindow(myApp)
+	})
+
+	dietBtn := widget.NewButton("Cheapest Diet", func() {
+		showDietSolverWindow(myApp)
+	})
+
+	pantryBtn := widget.NewButton("Pantry", func() {
+		showPantryWindow(myApp)
+	})
+
+	importBtn := widget.NewButton("Import Open Food Facts", func() {
+		showImportWindow(myApp)
+	})
+
+	searchFoodBtn := widget.NewButton("Search Foods", func() {
+		showSearchWindow(myApp)
+	})
+
+	viewStatsBtn := widget.NewButton("View Stats", func() {
+		showStatsWindow(myApp)
 	})
 
 	content := container.NewVBox(
@@ -454,6 +1007,17 @@ func showFoodWindow(myApp fyne.App) {
 		addFoodBtn,
 		addFoodDiaryBtn,
 		viewFoodBtn,
+		waterBtn,
+		weightBtn,
+		planBtn,
+		recipesBtn,
+		manageFoodsBtn,
+		goalsBtn,
+		priceHistoryBtn,
+		pantryBtn,
+		shoppingBtn,
+		dietBtn,
+		importBtn,
 		searchFoodBtn,
 		viewStatsBtn,
 		widget.NewButton("Back", func() {
@@ -466,6 +1030,42 @@ func showFoodWindow(myApp fyne.App) {
 	window.Show()
 }
 
+// showDatePicker asks for a year, month and day and only calls onPicked
+// with a real calendar date
+func showDatePicker(parent fyne.Window, initial time.Time, onPicked func(time.Time)) {
+	// Create spinners for year, month, and day
+	yearSpin := widget.NewEntry()
+	monthSpin := widget.NewEntry()
+	daySpin := widget.NewEntry()
+
+	yearSpin.SetText(fmt.Sprintf("%d", initial.Year()))
+	monthSpin.SetText(fmt.Sprintf("%02d", initial.Month()))
+	daySpin.SetText(fmt.Sprintf("%02d", initial.Day()))
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Year", yearSpin),
+		widget.NewFormItem("Month", monthSpin),
+		widget.NewFormItem("Day", daySpin),
+	}
+
+	dialog.ShowForm("Select Date", "OK", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		dateStr := fmt.Sprintf("%s-%s-%s",
+			strings.TrimSpace(yearSpin.Text),
+			strings.TrimSpace(monthSpin.Text),
+			strings.TrimSpace(daySpin.Text))
+		picked, err := time.ParseInLocation("2006-1-2", dateStr, time.Local)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s is not a valid date", dateStr), parent)
+			return
+		}
+		onPicked(picked)
+	}, parent)
+}
+
 func viewDiary(myApp fyne.App) fyne.Window {
 	window := myApp.NewWindow("Food Diary")
 	window.Resize(fyne.NewSize(500, 700))
@@ -473,91 +1073,285 @@ func viewDiary(myApp fyne.App) fyne.Window {
 	dateInput := widget.NewEntry()
 	dateInput.SetPlaceHolder("YYYY/MM/DD")
 
-	entriesText := widget.NewTextGrid()
-	entriesText.Resize(fyne.NewSize(450, 500)) // Set a specific size for the text grid
+	// Rows are either a meal heading or one diary entry
+	type diaryRow struct {
+		heading string
+		entryID int
+	}
+	var rows []diaryRow
+
+	// In select mode tapping an entry ticks it for copying instead of
+	// editing it
+	selected := make(map[int]bool)
+	selectCheck := widget.NewCheck("Select entries to copy", nil)
+
+	entriesList := widget.NewList(
+		func() int { return len(rows) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			row := rows[id]
+			label := item.(*widget.Label)
+
+			if row.heading != "" {
+				label.TextStyle = fyne.TextStyle{Bold: true}
+				label.SetText(row.heading)
+				return
+			}
+
+			label.TextStyle = fyne.TextStyle{}
+			idx := getDiaryEntryIndex(row.entryID)
+			if idx < 0 {
+				label.SetText("")
+				return
+			}
+			entry := dailyDiary.Entries[idx]
+			text := fmt.Sprintf("%s: %s - %.0f cal, $%.2f", entry.FoodName, entry.amountLabel(), entry.Calories, entry.Cost)
+			if entry.Time != "" {
+				text = entry.Time + " " + text
+			}
+			if selectCheck.Checked {
+				if selected[entry.ID] {
+					text = "☑ " + text
+				} else {
+					text = "☐ " + text
+				}
+			}
+			label.SetText(text)
+		},
+	)
+
+	entriesList.OnSelected = func(id widget.ListItemID) {
+		entriesList.UnselectAll()
+		if rows[id].heading != "" {
+			return
+		}
+		if selectCheck.Checked {
+			selected[rows[id].entryID] = !selected[rows[id].entryID]
+			entriesList.Refresh()
+			return
+		}
+		editDiaryEntry(window, rows[id].entryID)
+	}
+
+	selectCheck.OnChanged = func(bool) {
+		selected = make(map[int]bool)
+		entriesList.Refresh()
+	}
 
-	entriesScroll := container.NewScroll(entriesText)
-	entriesScroll.Resize(fyne.NewSize(480, 500)) // Make sure this is big enough to show multiple lines
+	entriesScroll := container.NewScroll(entriesList)
 	entriesScroll.SetMinSize(fyne.NewSize(480, 300))
 
 	totalCaloriesLabel := widget.NewLabel("")
 	totalCostLabel := widget.NewLabel("")
+	totalNutrientsLabel := widget.NewLabel("")
+
+	// Water drunk on the shown day against the daily target
+	waterProgress := widget.NewProgressBar()
+	waterLabel := widget.NewLabel("")
+	showWater := func(date string) {
+		total := waterOn(date)
+		waterProgress.Max = float64(waterLog.Target)
+		waterProgress.SetValue(math.Min(float64(total), float64(waterLog.Target)))
+		waterLabel.SetText(waterProgressText(total))
+	}
+
+	// Progress against the goal that applied on the shown day
+	calorieProgress := widget.NewProgressBar()
+	calorieRemainingLabel := widget.NewLabel("")
+	costProgress := widget.NewProgressBar()
+	costRemainingLabel := widget.NewLabel("")
+	goalContainer := container.NewVBox(
+		calorieProgress,
+		calorieRemainingLabel,
+		costProgress,
+		costRemainingLabel,
+	)
+
+	showGoalProgress := func(date string, calories, cost float64) {
+		goal, ok := goalForDate(date)
+		if !ok {
+			goalContainer.Hide()
+			return
+		}
+		goalContainer.Show()
+
+		calorieProgress.Max = goal.Calories
+		calorieProgress.SetValue(math.Min(calories, goal.Calories))
+		if calories <= goal.Calories {
+			calorieRemainingLabel.SetText(fmt.Sprintf("%.0f of %.0f cal goal, %.0f remaining", calories, goal.Calories, goal.Calories-calories))
+		} else {
+			calorieRemainingLabel.SetText(fmt.Sprintf("%.0f of %.0f cal goal, %.0f over", calories, goal.Calories, calories-goal.Calories))
+		}
+
+		if goal.Budget == 0 {
+			costProgress.Hide()
+			costRemainingLabel.Hide()
+			return
+		}
+		costProgress.Show()
+		costRemainingLabel.Show()
+		costProgress.Max = goal.Budget
+		costProgress.SetValue(math.Min(cost, goal.Budget))
+		if cost <= goal.Budget {
+			costRemainingLabel.SetText(fmt.Sprintf("$%.2f of $%.2f budget, $%.2f remaining", cost, goal.Budget, goal.Budget-cost))
+		} else {
+			costRemainingLabel.SetText(fmt.Sprintf("$%.2f of $%.2f budget, $%.2f over", cost, goal.Budget, cost-goal.Budget))
+		}
+	}
+
+	showMessage := func(message string) {
+		rows = []diaryRow{{heading: message}}
+		entriesList.Refresh()
+		totalCaloriesLabel.SetText("")
+		totalCostLabel.SetText("")
+		totalNutrientsLabel.SetText("")
+	}
 
 	// Function to update display
 	updateDisplay := func(dateStr string) {
 		if dateStr == "" {
-			entriesText.SetText("Please select a date")
-			totalCaloriesLabel.SetText("")
-			totalCostLabel.SetText("")
+			showMessage("Please select a date")
+			goalContainer.Hide()
 			return
 		}
 
-		var displayText strings.Builder
 		var totalCals float64
 		var totalCost float64
+		var totalNutrients Nutrients
 
-		// Find entries for selected date
+		// Find entries for selected date, grouped by meal
 		formattedDate := strings.ReplaceAll(dateStr, "/", "-")
-		hasEntries := false
+		entriesByMeal := make(map[MealSlot][]DiaryEntry)
 
 		for _, entry := range dailyDiary.Entries {
 			if entry.Date == formattedDate {
-				hasEntries = true
-				displayText.WriteString(fmt.Sprintf("%s: %dg\n", entry.FoodName, entry.Quantity))
-				displayText.WriteString(fmt.Sprintf("Calories: %.0f, Cost: $%.2f\n\n", entry.Calories, entry.Cost))
+				entriesByMeal[entry.Meal] = append(entriesByMeal[entry.Meal], entry)
 				totalCals += entry.Calories
 				totalCost += entry.Cost
+				totalNutrients = totalNutrients.Add(entry.Nutrients)
 			}
 		}
 
-		if !hasEntries {
-			entriesText.SetText("No entries for this date")
-			totalCaloriesLabel.SetText("")
-			totalCostLabel.SetText("")
-		} else {
-			entriesText.SetText(displayText.String())
-			totalCaloriesLabel.SetText(fmt.Sprintf("Total Calories: %.0f", totalCals))
-			totalCostLabel.SetText(fmt.Sprintf("Total Cost: $%.2f", totalCost))
+		showGoalProgress(formattedDate, totalCals, totalCost)
+		showWater(formattedDate)
+		if len(entriesByMeal) == 0 {
+			showMessage("No entries for this date")
+			return
+		}
+
+		rows = nil
+		for _, meal := range mealSlots {
+			mealEntries := entriesByMeal[meal]
+			if len(mealEntries) == 0 {
+				continue
+			}
+			sort.SliceStable(mealEntries, func(i, j int) bool {
+				return mealEntries[i].Time < mealEntries[j].Time
+			})
+
+			var mealCals, mealCost float64
+			for _, entry := range mealEntries {
+				mealCals += entry.Calories
+				mealCost += entry.Cost
+			}
+			rows = append(rows, diaryRow{heading: fmt.Sprintf("%s: %.0f cal, $%.2f", meal.Label(), mealCals, mealCost)})
+
+			for _, entry := range mealEntries {
+				rows = append(rows, diaryRow{entryID: entry.ID})
+			}
 		}
+
+		entriesList.Refresh()
+		totalCaloriesLabel.SetText(fmt.Sprintf("Total Calories: %.0f", totalCals))
+		totalCostLabel.SetText(fmt.Sprintf("Total Cost: $%.2f", totalCost))
+		totalNutrientsLabel.SetText(totalNutrients.Summary())
 	}
 
 	// Handle date input changes
 	dateInput.OnChanged = func(dateStr string) {
+		selected = make(map[int]bool)
 		updateDisplay(dateStr)
 	}
 
-	// Create calendar button
-	calendarBtn := widget.NewButton("📅", func() {
-		// Create spinners for year, month, and day
-		yearSpin := widget.NewEntry()
-		monthSpin := widget.NewEntry()
-		daySpin := widget.NewEntry()
+	copyDayBtn := widget.NewButton("Copy Day to Today", func() {
+		formattedDate := strings.ReplaceAll(dateInput.Text, "/", "-")
+		var dayEntries []DiaryEntry
+		for _, entry := range dailyDiary.Entries {
+			if entry.Date == formattedDate {
+				dayEntries = append(dayEntries, entry)
+			}
+		}
+		if len(dayEntries) == 0 {
+			dialog.ShowInformation("Copy Day", "There are no entries on this date to copy", window)
+			return
+		}
 
-		// Set current date as default
-		now := time.Now()
-		yearSpin.SetText(fmt.Sprintf("%d", now.Year()))
-		monthSpin.SetText(fmt.Sprintf("%02d", now.Month()))
-		daySpin.SetText(fmt.Sprintf("%02d", now.Day()))
+		today := time.Now().Format("2006-01-02")
+		dialog.ShowConfirm("Copy Day",
+			fmt.Sprintf("Log the %d entries from %s again today at current prices?", len(dayEntries), formattedDate),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				copied, err := copyDiaryEntries(dayEntries, today)
+				if err != nil {
+					dialog.ShowError(err, window)
+					return
+				}
+				dialog.ShowInformation("Copy Day", fmt.Sprintf("Copied %d entries to today", copied), window)
+			}, window)
+	})
 
-		// Create date selection form
-		dateForm := widget.NewForm(
-			widget.NewFormItem("Year", yearSpin),
-			widget.NewFormItem("Month", monthSpin),
-			widget.NewFormItem("Day", daySpin),
-		)
+	copySelectedBtn := widget.NewButton("Copy Selected to Date...", func() {
+		var chosen []DiaryEntry
+		for _, entry := range dailyDiary.Entries {
+			if selected[entry.ID] {
+				chosen = append(chosen, entry)
+			}
+		}
+		if len(chosen) == 0 {
+			dialog.ShowInformation("Copy Entries", "Tick \"Select entries to copy\" and tap the entries to copy first", window)
+			return
+		}
 
-		// Show dialog
-		dialog.ShowCustom("Select Date", "OK", dateForm, window)
+		showDatePicker(window, time.Now(), func(picked time.Time) {
+			if picked.After(time.Now()) {
+				dialog.ShowError(fmt.Errorf("cannot log food for a future date"), window)
+				return
+			}
+			copied, err := copyDiaryEntries(chosen, picked.Format("2006-01-02"))
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			selectCheck.SetChecked(false)
+			dialog.ShowInformation("Copy Entries",
+				fmt.Sprintf("Copied %d entries to %s", copied, picked.Format("2006-01-02")), window)
+		})
+	})
 
-		// Update date when OK is clicked
-		dateForm.OnSubmit = func() {
-			dateStr := fmt.Sprintf("%s/%s/%s", yearSpin.Text, monthSpin.Text, daySpin.Text)
-			dateInput.SetText(dateStr)
+	// Create calendar button
+	calendarBtn := widget.NewButton("📅", func() {
+		initial, err := time.Parse("2006-01-02", strings.ReplaceAll(dateInput.Text, "/", "-"))
+		if err != nil {
+			initial = time.Now()
 		}
+		showDatePicker(window, initial, func(picked time.Time) {
+			dateInput.SetText(picked.Format("2006/01/02"))
+		})
 	})
 
+	// Keep the open day current when entries are added, edited or removed
+	removeListener := addDiaryListener(func() {
+		updateDisplay(dateInput.Text)
+	})
+	window.SetOnClosed(removeListener)
+
 	// Create header with larger text
 	header := widget.NewLabelWithStyle("Food Diary", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	hint := widget.NewLabel("Tap an entry to edit or delete it")
 
 	// Create date selection container
 	dateContainer := container.NewBorder(
@@ -580,6 +1374,10 @@ func viewDiary(myApp fyne.App) fyne.Window {
 			container.NewVBox(
 				totalCaloriesLabel,
 				totalCostLabel,
+				totalNutrientsLabel,
+				goalContainer,
+				waterLabel,
+				waterProgress,
 			),
 		),
 	)
@@ -589,7 +1387,10 @@ func viewDiary(myApp fyne.App) fyne.Window {
 		container.NewPadded(header),
 		paddedDateContainer,
 		widget.NewSeparator(),
+		hint,
+		selectCheck,
 		entriesScroll,
+		container.NewGridWithColumns(2, copyDayBtn, copySelectedBtn),
 		summaryContainer,
 		container.NewPadded(backBtn),
 	)
@@ -604,6 +1405,145 @@ func viewDiary(myApp fyne.App) fyne.Window {
 	return window
 }
 
+func foodOptionLabel(food Food) string {
+	return fmt.Sprintf("%s (#%d)", food.Name, food.ID)
+}
+
+// editDiaryEntry lets the user change or delete a single diary entry
+func editDiaryEntry(parent fyne.Window, entryID int) {
+	idx := getDiaryEntryIndex(entryID)
+	if idx < 0 {
+		return
+	}
+	entry := dailyDiary.Entries[idx]
+
+	foodOptions := make([]string, 0, len(foods))
+	for _, food := range foods {
+		foodOptions = append(foodOptions, foodOptionLabel(food))
+	}
+	amount, unit := entry.loggedAmount()
+	quantityEntry := widget.NewEntry()
+	quantityEntry.SetText(strconv.FormatFloat(amount, 'f', -1, 64))
+	unitSelect := widget.NewSelect([]string{unit}, nil)
+	unitSelect.SetSelected(unit)
+
+	// Offer the units of whichever food is chosen, keeping the current unit
+	// when the new food has it too
+	foodSelect := widget.NewSelect(foodOptions, func(option string) {
+		for _, food := range foods {
+			if foodOptionLabel(food) != option {
+				continue
+			}
+			current := unitSelect.Selected
+			unitSelect.Options = food.unitNames()
+			if _, err := food.gramsPerUnit(current); err != nil {
+				current = unitGrams
+			}
+			unitSelect.SetSelected(current)
+			unitSelect.Refresh()
+			break
+		}
+	})
+	if food := getFoodByID(entry.FoodID); food != nil {
+		foodSelect.SetSelected(foodOptionLabel(*food))
+	}
+
+	dateEntry := widget.NewEntry()
+	dateEntry.SetText(entry.Date)
+
+	mealOptions := make([]string, 0, len(mealSlots))
+	for _, meal := range mealSlots {
+		mealOptions = append(mealOptions, meal.Label())
+	}
+	mealSelect := widget.NewSelect(mealOptions, nil)
+	mealSelect.SetSelected(entry.Meal.Label())
+
+	timeEntry := widget.NewEntry()
+	timeEntry.SetPlaceHolder("HH:MM (optional)")
+	timeEntry.SetText(entry.Time)
+
+	var editDialog *dialog.FormDialog
+
+	deleteBtn := widget.NewButton("Delete Entry", func() {
+		editDialog.Hide()
+		dialog.ShowConfirm("Delete Entry",
+			fmt.Sprintf("Delete %s (%s) from %s?", entry.FoodName, entry.amountLabel(), entry.Date),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				if err := deleteDiaryEntry(entryID); err != nil {
+					dialog.ShowError(err, parent)
+				}
+			}, parent)
+	})
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Food", foodSelect),
+		widget.NewFormItem("Quantity", container.NewBorder(nil, nil, nil, unitSelect, quantityEntry)),
+		widget.NewFormItem("Date", dateEntry),
+		widget.NewFormItem("Meal", mealSelect),
+		widget.NewFormItem("Time", timeEntry),
+		widget.NewFormItem("", deleteBtn),
+	}
+
+	editDialog = dialog.NewForm("Edit Entry", "Save", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		updated := entry
+		var selectedFood Food
+		for _, food := range foods {
+			if foodOptionLabel(food) == foodSelect.Selected {
+				updated.FoodID = food.ID
+				selectedFood = food
+				break
+			}
+		}
+
+		quantity, err := strconv.ParseFloat(strings.TrimSpace(quantityEntry.Text), 64)
+		if err != nil || quantity <= 0 {
+			dialog.ShowError(fmt.Errorf("please enter a valid quantity"), parent)
+			return
+		}
+		perUnit, err := selectedFood.gramsPerUnit(unitSelect.Selected)
+		if err != nil {
+			dialog.ShowError(err, parent)
+			return
+		}
+		updated.Quantity = int(math.Round(quantity * perUnit))
+		updated.Amount, updated.Unit = 0, ""
+		if unitSelect.Selected != unitGrams {
+			updated.Amount, updated.Unit = quantity, unitSelect.Selected
+		}
+
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(dateEntry.Text))
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("please enter the date as YYYY-MM-DD"), parent)
+			return
+		}
+		updated.Date = date.Format("2006-01-02")
+
+		updated.Meal = mealSlotFromLabel(mealSelect.Selected)
+
+		updated.Time = strings.TrimSpace(timeEntry.Text)
+		if updated.Time != "" {
+			parsed, err := time.Parse("15:04", updated.Time)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("please enter the time as HH:MM"), parent)
+				return
+			}
+			updated.Time = parsed.Format("15:04")
+		}
+
+		if err := updateDiaryEntry(updated); err != nil {
+			dialog.ShowError(err, parent)
+		}
+	}, parent)
+	editDialog.Show()
+}
+
 func showFoodMenu() {
 	fmt.Println("\n=== Food Tracker Menu ===")
 	fmt.Println("1. Add new food to database")
@@ -630,6 +1570,7 @@ func compareTrackMenu() {
 	fmt.Println("\n=== Compare Track Menu ===")
 	fmt.Println("What would you like to compare:\n ")
 	fmt.Println("1. Compare diet and symptoms")
+	fmt.Println("2. Return to Main Menu")
 	fmt.Print("Choose an Option by typing the number: ")
 }
 
@@ -645,6 +1586,56 @@ func handleCompareMenu() {
 		switch choice {
 		case "1":
 			compareDietSymptoms()
+		case "2":
+			return
+		default:
+			fmt.Println("Invalid option. Please try again.")
+		}
+	}
+}
+
+// showQuickSummary prints what has been logged today
+func showQuickSummary() {
+	today := time.Now().Format("2006-01-02")
+	var calories, cost float64
+	entries := 0
+	for _, entry := range dailyDiary.Entries {
+		if entry.Date == today {
+			calories += entry.Calories
+			cost += entry.Cost
+			entries++
+		}
+	}
+
+	fmt.Printf("\n=== Today (%s) ===\n", today)
+	fmt.Printf("Food: %d entries, %.0f calories, $%.2f\n", entries, calories, cost)
+	fmt.Printf("Symptoms: %d entries\n", len(symptomEntriesOn(today)))
+	if len(weightLog.Entries) > 0 {
+		fmt.Println(weightReport())
+	}
+}
+
+// handleMainMenu runs the terminal menus, for use without the GUI
+func handleMainMenu() {
+	for {
+		showMainMenu()
+		choice := readInput("")
+
+		switch choice {
+		case "1":
+			showQuickSummary()
+		case "2":
+			handleFoodMenu()
+		case "3":
+			HandleSymptomMenu()
+		case "4":
+			handleCompareMenu()
+		case "5":
+			HandleFinanceMenu()
+		case "6":
+			return
+		default:
+			fmt.Println("Invalid option. Please try again.")
 		}
 	}
 }
@@ -695,6 +1686,44 @@ func loadInitialData() {
 		log.Printf("Warning: Failed to load existing diary data: %v", err)
 		dailyDiary.Entries = make([]DiaryEntry, 0)
 	}
+
+	if err := loadGoalsFromFile(); err != nil {
+		log.Printf("Warning: Failed to load existing goal data: %v", err)
+		goals = make([]Goal, 0)
+	}
+
+	if err := loadWaterFromFile(); err != nil {
+		log.Printf("Warning: Failed to load existing water data: %v", err)
+		waterLog = WaterLog{Target: defaultWaterTarget, Entries: make([]WaterEntry, 0)}
+	}
+
+	if err := loadWeightFromFile(); err != nil {
+		log.Printf("Warning: Failed to load existing weight data: %v", err)
+		weightLog = WeightLog{Entries: make([]WeightEntry, 0)}
+	}
+
+	if err := loadPantryFromFile(); err != nil {
+		log.Printf("Warning: Failed to load existing pantry data: %v", err)
+		purchases = make([]Purchase, 0)
+	}
+
+	if err := loadPlanFromFile(); err != nil {
+		log.Printf("Warning: Failed to load existing meal plan data: %v", err)
+		mealPlan = make([]DiaryEntry, 0)
+	}
+
+	// Load recipes and bring them in line with any ingredient changes,
+	// including prices recorded ahead of time that now apply
+	if err := loadRecipesFromFile(); err != nil {
+		log.Printf("Warning: Failed to load existing recipe data: %v", err)
+		recipes = make([]Recipe, 0)
+	}
+	pricesChanged := applyCurrentPrices()
+	if refreshRecipes() || pricesChanged {
+		if err := saveToFile(); err != nil {
+			log.Printf("Warning: Failed to save refreshed recipes: %v", err)
+		}
+	}
 }
 
 func addFoodToDatabase(myApp fyne.App) fyne.Window {
@@ -713,14 +1742,69 @@ func addFoodToDatabase(myApp fyne.App) fyne.Window {
 	caloriesEntry := widget.NewEntry()
 	caloriesEntry.SetPlaceHolder("Total Calories")
 
+	// Nutrients are optional and, like calories, are for the whole quantity
+	nutrientInputs := newNutrientInputs()
+
+	// Optional category and free-form tags
+	categoryEntry := widget.NewSelectEntry(allCategories())
+	categoryEntry.SetPlaceHolder("Category (optional, e.g. dairy)")
+	tagsEntry := widget.NewEntry()
+	tagsEntry.SetPlaceHolder("Tags, comma separated (optional)")
+
+	// Optional portion units, one per line, and density for liquids
+	unitsEntry := widget.NewMultiLineEntry()
+	unitsEntry.SetPlaceHolder("Units, e.g.\negg = 50 g\ncup = 240 ml")
+	densityEntry := widget.NewEntry()
+	densityEntry.SetPlaceHolder("Density g/ml (optional, for liquids)")
+
 	// Create result label for feedback
 	resultLabel := widget.NewLabel("")
 
+	// A typed or scanned barcode prefills the form from imported products
+	barcodeEntry := widget.NewEntry()
+	barcodeEntry.SetPlaceHolder("Barcode (optional)")
+	barcodeEntry.OnChanged = func(code string) {
+		if !validBarcode(code) {
+			return
+		}
+		if existing := getFoodByBarcode(code); existing != nil {
+			resultLabel.SetText(fmt.Sprintf("This barcode is already used by %s", existing.Name))
+			return
+		}
+		product, ok := lookupBarcode(code)
+		if !ok {
+			resultLabel.SetText("Barcode not found in imported products")
+			return
+		}
+
+		var food Food
+		applyOFFProduct(&food, product)
+		nameEntry.SetText(food.Name)
+		quantityEntry.SetText(strconv.Itoa(food.Quantity))
+		caloriesEntry.SetText(strconv.FormatFloat(math.Round(food.Calories), 'f', -1, 64))
+		nutrientInputs.SetNutrients(food.Nutrients)
+		resultLabel.SetText(fmt.Sprintf("Found %s %s (%.0f cal/100g). Enter the price you paid.",
+			product.Brand, product.Name, product.CaloriesPer100g))
+	}
+
 	// Save button with validation and processing
 	saveBtn := widget.NewButton("Save Food", func() {
 		// Validate and process inputs
 		var food Food
 
+		// Get barcode
+		if barcode := strings.TrimSpace(barcodeEntry.Text); barcode != "" {
+			if !validBarcode(barcode) {
+				resultLabel.SetText("Invalid barcode. Please check the digits")
+				return
+			}
+			if existing := getFoodByBarcode(barcode); existing != nil {
+				resultLabel.SetText(fmt.Sprintf("This barcode is already used by %s", existing.Name))
+				return
+			}
+			food.Barcode = normalizeBarcode(barcode)
+		}
+
 		// Get name
 		food.Name = nameEntry.Text
 		if food.Name == "" {
@@ -752,18 +1836,35 @@ func addFoodToDatabase(myApp fyne.App) fyne.Window {
 		}
 		food.Calories = calories
 
+		// Parse optional nutrients
+		if err := nutrientInputs.Parse(&food.Nutrients); err != nil {
+			resultLabel.SetText(err.Error())
+			return
+		}
+
+		food.Category = normalizeTag(categoryEntry.Text)
+		food.Tags = parseTags(tagsEntry.Text)
+
+		// Parse optional units and density
+		food.Units, err = parsePortionUnits(unitsEntry.Text)
+		if err != nil {
+			resultLabel.SetText(err.Error())
+			return
+		}
+		food.Density, err = parseDensity(densityEntry.Text)
+		if err != nil {
+			resultLabel.SetText(err.Error())
+			return
+		}
+
 		// Calculate derived values
-		food.CalPerDollar = food.Calories / food.Price
-		food.CalPer100g = (food.Calories / float64(food.Quantity)) * 100
+		updateFoodStats(&food)
 
 		// Set ID based on existing foods
-		maxID := 0
-		for _, f := range foods {
-			if f.ID > maxID {
-				maxID = f.ID
-			}
-		}
-		food.ID = maxID + 1
+		food.ID = nextFoodID()
+
+		// Start the price history at today's price
+		recordPrice(&food, time.Now().Format("2006-01-02"), food.Price)
 
 		// Add to foods slice and save to file
 		foods = append(foods, food)
@@ -773,13 +1874,14 @@ func addFoodToDatabase(myApp fyne.App) fyne.Window {
 		}
 
 		// Show success message with details
-		resultText := fmt.Sprintf("Added: %s\nPrice: $%.2f\nQuantity: %dg\nCalories: %.0f\nCalories per Dollar: %.0f\nCalories per 100g: %.0f",
+		resultText := fmt.Sprintf("Added: %s\nPrice: $%.2f\nQuantity: %dg\nCalories: %.0f\nCalories per Dollar: %.0f\nCalories per 100g: %.0f\n%s",
 			food.Name,
 			food.Price,
 			food.Quantity,
 			food.Calories,
 			food.CalPerDollar,
-			food.CalPer100g)
+			food.CalPer100g,
+			food.Nutrients.Summary()) + "\n" + tagsSummary(food)
 		resultLabel.SetText(resultText)
 
 		// Clear input fields
@@ -787,6 +1889,13 @@ func addFoodToDatabase(myApp fyne.App) fyne.Window {
 		priceEntry.SetText("")
 		quantityEntry.SetText("")
 		caloriesEntry.SetText("")
+		barcodeEntry.SetText("")
+		categoryEntry.SetText("")
+		categoryEntry.SetOptions(allCategories())
+		tagsEntry.SetText("")
+		unitsEntry.SetText("")
+		densityEntry.SetText("")
+		nutrientInputs.SetNutrients(Nutrients{})
 	})
 
 	// Create back button
@@ -797,17 +1906,23 @@ func addFoodToDatabase(myApp fyne.App) fyne.Window {
 	// Create layout
 	content := container.NewVBox(
 		widget.NewLabel("Add New Food"),
+		barcodeEntry,
 		nameEntry,
 		priceEntry,
 		quantityEntry,
 		caloriesEntry,
+		nutrientInputs.Container(),
+		categoryEntry,
+		tagsEntry,
+		unitsEntry,
+		densityEntry,
 		saveBtn,
 		resultLabel,
 		backBtn,
 	)
 
 	window.SetContent(content)
-	window.Resize(fyne.NewSize(300, 400))
+	window.Resize(fyne.NewSize(300, 600))
 	window.Show()
 	return window
 }
@@ -859,16 +1974,26 @@ func showSymptomWindow(myApp fyne.App) {
 	window := myApp.NewWindow("Symptom Menu")
 
 	addSymptomBtn := widget.NewButton("Add Symptom", func() {
-		// Implement add symptom functionality
+		showSymptomsWindow(myApp)
+	})
+
+	checkInBtn := widget.NewButton("Daily Check-in", func() {
+		showSymptomCheckInWindow(myApp)
+	})
+
+	logSymptomBtn := widget.NewButton("Log Symptom", func() {
+		showLogSymptomWindow(myApp)
 	})
 
 	viewSymptomBtn := widget.NewButton("View Symptom Diary", func() {
-		// Implement view symptom diary functionality
+		showSymptomDiaryWindow(myApp)
 	})
 
 	content := container.NewVBox(
 		widget.NewLabel("Symptom Menu"),
+		checkInBtn,
 		addSymptomBtn,
+		logSymptomBtn,
 		viewSymptomBtn,
 		widget.NewButton("Back", func() {
 			window.Close()
@@ -876,7 +2001,7 @@ func showSymptomWindow(myApp fyne.App) {
 	)
 
 	window.SetContent(content)
-	window.Resize(fyne.NewSize(300, 200))
+	window.Resize(fyne.NewSize(300, 300))
 	window.Show()
 }
 
@@ -926,7 +2051,14 @@ func showFinanceWindow(myApp fyne.App) {
 }
 
 func main() {
+	terminal := flag.Bool("terminal", false, "use the terminal menus instead of the GUI")
+	flag.Parse()
+
 	loadInitialData()
+	if *terminal {
+		handleMainMenu()
+		return
+	}
 
 	myApp := app.New()
 	mainWindow := createMainWindow(myApp)
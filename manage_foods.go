@@ -0,0 +1,475 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// foodReferences counts the diary entries and recipes that use a food
+func foodReferences(id int) (entryCount, recipeCount int) {
+	for _, entry := range dailyDiary.Entries {
+		if entry.FoodID == id {
+			entryCount++
+		}
+	}
+	for _, r := range recipes {
+		for _, ing := range r.Ingredients {
+			if ing.FoodID == id {
+				recipeCount++
+				break
+			}
+		}
+	}
+	return entryCount, recipeCount
+}
+
+// saveFoodDatabase writes every file a food change can touch and refreshes
+// any open diary windows
+func saveFoodDatabase() error {
+	if err := saveToFile(); err != nil {
+		return err
+	}
+	if err := saveRecipesToFile(); err != nil {
+		return err
+	}
+	if err := saveDiaryToFile(); err != nil {
+		return err
+	}
+	notifyDiaryChanged()
+	return nil
+}
+
+// saveFoodChanges stores an edited food and recomputes everything derived
+// from it. When updateEntries is set, diary entries for the food take its
+// new name and are repriced at the price in effect on their dates.
+func saveFoodChanges(updated Food, updateEntries bool) error {
+	food := getFoodByID(updated.ID)
+	if food == nil {
+		return fmt.Errorf("food %d not found", updated.ID)
+	}
+
+	// A new price is added to the history so past entries keep theirs.
+	// Prices are compared to the cent so an unchanged form records nothing.
+	if math.Round(updated.Price*100) != math.Round(food.Price*100) {
+		newPrice := updated.Price
+		updated.Price = food.Price
+		recordPrice(&updated, time.Now().Format("2006-01-02"), newPrice)
+	} else {
+		updated.Price = food.Price
+	}
+	updateFoodStats(&updated)
+	*food = updated
+
+	// Recipe foods take their name from the recipe
+	for i := range recipes {
+		if recipes[i].FoodID == updated.ID {
+			recipes[i].Name = updated.Name
+		}
+	}
+	refreshRecipes()
+
+	if updateEntries {
+		for i, entry := range dailyDiary.Entries {
+			if entry.FoodID == updated.ID {
+				dailyDiary.Entries[i] = recomputeDiaryEntry(entry, *food)
+			}
+		}
+	}
+
+	return saveFoodDatabase()
+}
+
+// removeFood deletes a food. When repointTo is set, diary entries and recipe
+// ingredients move to that food; otherwise entries keep their logged values
+// and the food is dropped from recipes.
+func removeFood(id, repointTo int) error {
+	if getFoodByID(id) == nil {
+		return fmt.Errorf("food %d not found", id)
+	}
+
+	var target *Food
+	if repointTo != 0 {
+		if repointTo == id {
+			return fmt.Errorf("cannot merge a food into itself")
+		}
+		if target = getFoodByID(repointTo); target == nil {
+			return fmt.Errorf("food %d not found", repointTo)
+		}
+		// Its recipes would end up using themselves
+		if recipeUses(target.ID, id) {
+			return fmt.Errorf("cannot merge into %s because it is a recipe made with this food", target.Name)
+		}
+	}
+
+	if target != nil {
+		for i, entry := range dailyDiary.Entries {
+			if entry.FoodID == id {
+				entry.FoodID = target.ID
+				dailyDiary.Entries[i] = recomputeDiaryEntry(entry, *target)
+			}
+		}
+	}
+
+	// Fix up recipes: drop the recipe built on this food and re-point or
+	// remove it as an ingredient
+	newRecipes := make([]Recipe, 0, len(recipes))
+	for _, r := range recipes {
+		if r.FoodID == id {
+			continue
+		}
+		ingredients := make([]RecipeIngredient, 0, len(r.Ingredients))
+		for _, ing := range r.Ingredients {
+			if ing.FoodID == id {
+				if target == nil {
+					continue
+				}
+				ing.FoodID = target.ID
+			}
+			ingredients = append(ingredients, ing)
+		}
+		r.Ingredients = ingredients
+		newRecipes = append(newRecipes, r)
+	}
+	recipes = newRecipes
+
+	newFoods := make([]Food, 0, len(foods))
+	for _, f := range foods {
+		if f.ID != id {
+			newFoods = append(newFoods, f)
+		}
+	}
+	foods = newFoods
+	refreshRecipes()
+
+	// Planned items follow a merge; otherwise they stay for the record
+	if target != nil {
+		for i, entry := range mealPlan {
+			if entry.FoodID == id {
+				entry.FoodID = target.ID
+				mealPlan[i] = recomputeDiaryEntry(entry, *target)
+				mealPlan[i].Planned = true
+				mealPlan[i].LoggedID = entry.LoggedID
+			}
+		}
+		if err := savePlanToFile(); err != nil {
+			return err
+		}
+	}
+
+	// Pantry stock moves with the entries or goes with the food
+	newPurchases := make([]Purchase, 0, len(purchases))
+	for _, p := range purchases {
+		if p.FoodID == id {
+			if target == nil {
+				continue
+			}
+			p.FoodID = target.ID
+		}
+		newPurchases = append(newPurchases, p)
+	}
+	purchases = newPurchases
+	if err := savePantryToFile(); err != nil {
+		return err
+	}
+
+	return saveFoodDatabase()
+}
+
+func showManageFoodsWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Manage Foods")
+
+	nameEntry := widget.NewEntry()
+	barcodeEntry := widget.NewEntry()
+	priceEntry := widget.NewEntry()
+	quantityEntry := widget.NewEntry()
+	caloriesEntry := widget.NewEntry()
+	nutrientInputs := newNutrientInputs()
+	categoryEntry := widget.NewSelectEntry(allCategories())
+	tagsEntry := widget.NewEntry()
+	tagsEntry.SetPlaceHolder("Comma separated")
+	unitsEntry := widget.NewMultiLineEntry()
+	unitsEntry.SetPlaceHolder("egg = 50 g\ncup = 240 ml")
+	densityEntry := widget.NewEntry()
+	densityEntry.SetPlaceHolder("Optional, for liquids")
+	updateEntriesCheck := widget.NewCheck("Also update existing diary entries", nil)
+	recipeNote := widget.NewLabel("Price, quantity and nutrition of a recipe come from its ingredients")
+	recipeNote.Hide()
+	statusLabel := widget.NewLabel("")
+
+	editForm := widget.NewForm(
+		widget.NewFormItem("Name", nameEntry),
+		widget.NewFormItem("Barcode", barcodeEntry),
+		widget.NewFormItem("Price today ($)", priceEntry),
+		widget.NewFormItem("Quantity (g)", quantityEntry),
+		widget.NewFormItem("Calories", caloriesEntry),
+		widget.NewFormItem("Nutrients", nutrientInputs.Container()),
+		widget.NewFormItem("Category", categoryEntry),
+		widget.NewFormItem("Tags", tagsEntry),
+		widget.NewFormItem("Units", unitsEntry),
+		widget.NewFormItem("Density (g/ml)", densityEntry),
+	)
+
+	selectedID := 0
+
+	var foodList *widget.List
+	foodList = widget.NewList(
+		func() int { return len(foods) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			food := foods[id]
+			item.(*widget.Label).SetText(fmt.Sprintf("%s ($%.2f/%dg, %.0f cal)", food.Name, food.Price, food.Quantity, food.Calories))
+		},
+	)
+
+	showFood := func(food Food) {
+		selectedID = food.ID
+		nameEntry.SetText(food.Name)
+		barcodeEntry.SetText(food.Barcode)
+		priceEntry.SetText(strconv.FormatFloat(food.Price, 'f', -1, 64))
+		quantityEntry.SetText(strconv.Itoa(food.Quantity))
+		caloriesEntry.SetText(strconv.FormatFloat(food.Calories, 'f', -1, 64))
+		nutrientInputs.SetNutrients(food.Nutrients)
+		categoryEntry.SetOptions(allCategories())
+		categoryEntry.SetText(food.Category)
+		tagsEntry.SetText(formatTags(food.Tags))
+		unitsEntry.SetText(formatPortionUnits(food.Units))
+		densityEntry.SetText(formatDensity(food.Density))
+		updateEntriesCheck.SetChecked(false)
+
+		entryCount, recipeCount := foodReferences(food.ID)
+		statusLabel.SetText(fmt.Sprintf("Used by %d diary entries and %d recipes", entryCount, recipeCount))
+
+		if food.RecipeID != 0 {
+			recipeNote.Show()
+		} else {
+			recipeNote.Hide()
+		}
+	}
+
+	clearSelection := func() {
+		selectedID = 0
+		nameEntry.SetText("")
+		barcodeEntry.SetText("")
+		priceEntry.SetText("")
+		quantityEntry.SetText("")
+		caloriesEntry.SetText("")
+		nutrientInputs.SetNutrients(Nutrients{})
+		categoryEntry.SetText("")
+		tagsEntry.SetText("")
+		unitsEntry.SetText("")
+		densityEntry.SetText("")
+		recipeNote.Hide()
+		foodList.UnselectAll()
+		foodList.Refresh()
+	}
+
+	foodList.OnSelected = func(id widget.ListItemID) {
+		showFood(foods[id])
+	}
+
+	saveBtn := widget.NewButton("Save Changes", func() {
+		food := getFoodByID(selectedID)
+		if food == nil {
+			statusLabel.SetText("Please select a food")
+			return
+		}
+		updated := *food
+
+		updated.Name = strings.TrimSpace(nameEntry.Text)
+		if updated.Name == "" {
+			statusLabel.SetText("Please enter a food name")
+			return
+		}
+
+		updated.Barcode = ""
+		if barcode := strings.TrimSpace(barcodeEntry.Text); barcode != "" {
+			if !validBarcode(barcode) {
+				statusLabel.SetText("Invalid barcode. Please check the digits")
+				return
+			}
+			if existing := getFoodByBarcode(barcode); existing != nil && existing.ID != updated.ID {
+				statusLabel.SetText("This barcode is already used by " + existing.Name)
+				return
+			}
+			updated.Barcode = normalizeBarcode(barcode)
+		}
+
+		if food.RecipeID == 0 {
+			price, err := strconv.ParseFloat(priceEntry.Text, 64)
+			if err != nil || price < 0 {
+				statusLabel.SetText("Invalid price. Please enter a number")
+				return
+			}
+			updated.Price = price
+
+			quantity, err := strconv.Atoi(quantityEntry.Text)
+			if err != nil || quantity <= 0 {
+				statusLabel.SetText("Invalid quantity. Please enter a number")
+				return
+			}
+			updated.Quantity = quantity
+
+			calories, err := strconv.ParseFloat(caloriesEntry.Text, 64)
+			if err != nil || calories < 0 {
+				statusLabel.SetText("Invalid calories. Please enter a number")
+				return
+			}
+			updated.Calories = calories
+
+			if err := nutrientInputs.Parse(&updated.Nutrients); err != nil {
+				statusLabel.SetText(err.Error())
+				return
+			}
+		}
+
+		updated.Category = normalizeTag(categoryEntry.Text)
+		updated.Tags = parseTags(tagsEntry.Text)
+
+		units, err := parsePortionUnits(unitsEntry.Text)
+		if err != nil {
+			statusLabel.SetText(err.Error())
+			return
+		}
+		updated.Units = units
+
+		updated.Density, err = parseDensity(densityEntry.Text)
+		if err != nil {
+			statusLabel.SetText(err.Error())
+			return
+		}
+
+		if err := saveFoodChanges(updated, updateEntriesCheck.Checked); err != nil {
+			statusLabel.SetText("Error saving food: " + err.Error())
+			return
+		}
+
+		saved := *getFoodByID(selectedID)
+		foodList.Refresh()
+		showFood(saved)
+		statusLabel.SetText(fmt.Sprintf("Saved %s (%.0f cal/$, %.0f cal/100g)",
+			saved.Name, saved.CalPerDollar, saved.CalPer100g))
+	})
+
+	// otherFoodOptions lists every food except the selected one
+	otherFoodOptions := func() []string {
+		options := make([]string, 0, len(foods))
+		for _, f := range foods {
+			if f.ID != selectedID {
+				options = append(options, foodOptionLabel(f))
+			}
+		}
+		return options
+	}
+
+	foodIDFromOption := func(option string) int {
+		for _, f := range foods {
+			if foodOptionLabel(f) == option {
+				return f.ID
+			}
+		}
+		return 0
+	}
+
+	deleteBtn := widget.NewButton("Delete Food", func() {
+		food := getFoodByID(selectedID)
+		if food == nil {
+			statusLabel.SetText("Please select a food")
+			return
+		}
+		name := food.Name
+
+		const keepEntries = "(keep entries as logged)"
+		entryCount, recipeCount := foodReferences(food.ID)
+		repointSelect := widget.NewSelect(append([]string{keepEntries}, otherFoodOptions()...), nil)
+		repointSelect.SetSelected(keepEntries)
+
+		warning := widget.NewLabel(fmt.Sprintf("%s is used by %d diary entries and %d recipes.\nEntries can be moved to another food, otherwise they keep\ntheir logged values and the food is removed from recipes.",
+			name, entryCount, recipeCount))
+
+		content := container.NewVBox(warning)
+		if entryCount > 0 || recipeCount > 0 {
+			content.Add(widget.NewForm(widget.NewFormItem("Move to", repointSelect)))
+		}
+
+		dialog.ShowCustomConfirm("Delete "+name, "Delete", "Cancel", content, func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := removeFood(selectedID, foodIDFromOption(repointSelect.Selected)); err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			clearSelection()
+			statusLabel.SetText("Deleted " + name)
+		}, window)
+	})
+
+	mergeBtn := widget.NewButton("Merge Into...", func() {
+		food := getFoodByID(selectedID)
+		if food == nil {
+			statusLabel.SetText("Please select a food")
+			return
+		}
+		name := food.Name
+
+		targetSelect := widget.NewSelect(otherFoodOptions(), nil)
+		entryCount, recipeCount := foodReferences(food.ID)
+		content := container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("%s will be removed and its %d diary entries and %d recipes\nwill use the food below, repriced from its values.",
+				name, entryCount, recipeCount)),
+			widget.NewForm(widget.NewFormItem("Keep", targetSelect)),
+		)
+
+		dialog.ShowCustomConfirm("Merge "+name, "Merge", "Cancel", content, func(ok bool) {
+			if !ok {
+				return
+			}
+			targetID := foodIDFromOption(targetSelect.Selected)
+			if targetID == 0 {
+				dialog.ShowError(fmt.Errorf("please choose a food to merge into"), window)
+				return
+			}
+			if err := removeFood(selectedID, targetID); err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			clearSelection()
+			statusLabel.SetText(fmt.Sprintf("Merged %s into %s", name, getFoodByID(targetID).Name))
+		}, window)
+	})
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	listScroll := container.NewScroll(foodList)
+	listScroll.SetMinSize(fyne.NewSize(250, 400))
+
+	details := container.NewVBox(
+		editForm,
+		recipeNote,
+		updateEntriesCheck,
+		container.NewGridWithColumns(3, saveBtn, deleteBtn, mergeBtn),
+		statusLabel,
+		backBtn,
+	)
+
+	content := container.NewBorder(
+		widget.NewLabel("Manage Foods"), nil, listScroll, nil,
+		container.NewVScroll(details),
+	)
+
+	window.SetContent(content)
+	window.Resize(fyne.NewSize(750, 600))
+	window.Show()
+	return window
+}
@@ -0,0 +1,612 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// offProductFile holds the products imported from an Open Food Facts dump so
+// barcodes can be looked up without network access
+const offProductFile = "off_products.json"
+
+// OFFProduct is the part of an Open Food Facts product we use. Calories and
+// nutrients are per 100g.
+type OFFProduct struct {
+	Barcode         string    `json:"barcode"`
+	Name            string    `json:"name"`
+	Brand           string    `json:"brand,omitempty"`
+	CaloriesPer100g float64   `json:"calories_per_100g"`
+	PackGrams       int       `json:"pack_grams,omitempty"`
+	Nutrients       Nutrients `json:"nutrients_per_100g"`
+}
+
+// offCatalogueLimit caps how many products are kept for barcode lookups on
+// top of the ones the food database uses. A full dump has millions.
+const offCatalogueLimit = 20000
+
+type offImportResult struct {
+	Products int // read from the dump
+	Kept     int // stored in the local catalogue
+	Updated  int
+	Created  int
+	Skipped  int
+	NotFound []string // requested barcodes missing from the dump
+}
+
+// offProgress is reported while a dump is being read
+type offProgress struct {
+	BytesRead  int64
+	BytesTotal int64
+	Products   int
+}
+
+// offScan is what reading a dump keeps: the products whose barcodes were
+// asked for, and up to a limit of others
+type offScan struct {
+	Products map[string]OFFProduct
+	Read     int
+	Skipped  int
+}
+
+var (
+	offProducts       map[string]OFFProduct
+	offProductsLoaded bool
+)
+
+// Save and load functions for the imported products
+func saveOFFProducts() error {
+	file, err := os.Create(offProductFile)
+	if err != nil {
+		return fmt.Errorf("error creating product file: %v", err)
+	}
+	defer file.Close()
+
+	products := make([]OFFProduct, 0, len(offProducts))
+	for _, p := range offProducts {
+		products = append(products, p)
+	}
+
+	encoder := json.NewEncoder(file)
+	if err := encoder.Encode(products); err != nil {
+		return fmt.Errorf("error encoding product data: %v", err)
+	}
+	return nil
+}
+
+func loadOFFProducts() error {
+	offProductsLoaded = true
+	offProducts = make(map[string]OFFProduct)
+
+	file, err := os.Open(offProductFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error opening product file: %v", err)
+	}
+	defer file.Close()
+
+	var products []OFFProduct
+	decoder := json.NewDecoder(file)
+	if err := decoder.Decode(&products); err != nil {
+		return fmt.Errorf("error decoding product data: %v", err)
+	}
+	for _, p := range products {
+		offProducts[p.Barcode] = p
+	}
+	return nil
+}
+
+// normalizeBarcode keeps only digits and writes 12 digit UPC-A codes as the
+// equivalent EAN-13 so both forms match
+func normalizeBarcode(code string) string {
+	var digits strings.Builder
+	for _, r := range code {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	normalized := digits.String()
+	if len(normalized) == 12 {
+		normalized = "0" + normalized
+	}
+	return normalized
+}
+
+// validBarcode checks the length and check digit of an EAN-8, UPC-A, EAN-13
+// or GTIN-14 code
+func validBarcode(code string) bool {
+	code = normalizeBarcode(code)
+	if len(code) != 8 && len(code) != 13 && len(code) != 14 {
+		return false
+	}
+
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if (len(code)-2-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return (10-sum%10)%10 == int(code[len(code)-1]-'0')
+}
+
+// lookupBarcode finds an imported product, loading the catalogue on first use
+func lookupBarcode(code string) (OFFProduct, bool) {
+	if !offProductsLoaded {
+		if err := loadOFFProducts(); err != nil {
+			return OFFProduct{}, false
+		}
+	}
+	product, ok := offProducts[normalizeBarcode(code)]
+	return product, ok
+}
+
+func getFoodByBarcode(code string) *Food {
+	code = normalizeBarcode(code)
+	if code == "" {
+		return nil
+	}
+	for i := range foods {
+		if normalizeBarcode(foods[i].Barcode) == code {
+			return &foods[i]
+		}
+	}
+	return nil
+}
+
+var packQuantityPattern = regexp.MustCompile(`(?i)(?:(\d+)\s*[x×]\s*)?(\d+(?:[.,]\d+)?)\s*(kg|g|ml|cl|l)\b`)
+
+// parsePackGrams reads an Open Food Facts quantity such as "500 g", "1.5 kg"
+// or "6 x 330 ml". Volumes are treated as grams.
+func parsePackGrams(quantity string) int {
+	match := packQuantityPattern.FindStringSubmatch(quantity)
+	if match == nil {
+		return 0
+	}
+
+	amount, err := strconv.ParseFloat(strings.Replace(match[2], ",", ".", 1), 64)
+	if err != nil {
+		return 0
+	}
+	switch strings.ToLower(match[3]) {
+	case "kg", "l":
+		amount *= 1000
+	case "cl":
+		amount *= 10
+	}
+	if match[1] != "" {
+		count, _ := strconv.Atoi(match[1])
+		amount *= float64(count)
+	}
+	return int(amount + 0.5)
+}
+
+// offNumber reads a dump value that may be a JSON number or a string
+func offNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// offProductFromFields builds a product from the dump's field names, which
+// are the same in the CSV export and the JSONL nutriments
+func offProductFromFields(field func(name string) (interface{}, bool)) (OFFProduct, bool) {
+	text := func(name string) string {
+		if v, ok := field(name); ok {
+			if s, ok := v.(string); ok {
+				return strings.TrimSpace(s)
+			}
+		}
+		return ""
+	}
+	number := func(name string) (float64, bool) {
+		if v, ok := field(name); ok {
+			return offNumber(v)
+		}
+		return 0, false
+	}
+
+	product := OFFProduct{
+		Barcode: normalizeBarcode(text("code")),
+		Name:    text("product_name"),
+		Brand:   text("brands"),
+	}
+	if product.Barcode == "" || product.Name == "" {
+		return product, false
+	}
+
+	if kcal, ok := number("energy-kcal_100g"); ok {
+		product.CaloriesPer100g = kcal
+	} else if kj, ok := number("energy_100g"); ok {
+		product.CaloriesPer100g = kj / 4.184
+	} else {
+		return product, false
+	}
+
+	if grams, ok := number("product_quantity"); ok && grams > 0 {
+		product.PackGrams = int(grams + 0.5)
+	} else {
+		product.PackGrams = parsePackGrams(text("quantity"))
+	}
+
+	product.Nutrients.Protein, _ = number("proteins_100g")
+	product.Nutrients.Carbs, _ = number("carbohydrates_100g")
+	product.Nutrients.Fat, _ = number("fat_100g")
+	product.Nutrients.Fibre, _ = number("fiber_100g")
+	product.Nutrients.Sugar, _ = number("sugars_100g")
+	if sodium, ok := number("sodium_100g"); ok {
+		product.Nutrients.Sodium = sodium * 1000 // grams to milligrams
+	}
+	return product, true
+}
+
+// readOFFJSONL reads a JSONL dump, one product object per line
+func readOFFJSONL(r io.Reader, add func(OFFProduct)) (skipped int, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024)
+
+	for scanner.Scan() {
+		var raw map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &raw); err != nil {
+			skipped++
+			continue
+		}
+		nutriments, _ := raw["nutriments"].(map[string]interface{})
+
+		product, ok := offProductFromFields(func(name string) (interface{}, bool) {
+			if v, ok := raw[name]; ok {
+				return v, true
+			}
+			v, ok := nutriments[name]
+			return v, ok
+		})
+		if !ok {
+			skipped++
+			continue
+		}
+		add(product)
+	}
+	if err := scanner.Err(); err != nil {
+		return skipped, fmt.Errorf("error reading dump: %v", err)
+	}
+	return skipped, nil
+}
+
+// readOFFCSV reads the CSV export, which is tab separated despite its name
+func readOFFCSV(r io.Reader, add func(OFFProduct)) (skipped int, err error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return 0, fmt.Errorf("error reading dump: %v", err)
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.Comma = ','
+	if strings.Contains(string(header), "\t") {
+		reader.Comma = '\t'
+	}
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	columns, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("error reading dump header: %v", err)
+	}
+	index := make(map[string]int, len(columns))
+	for i, name := range columns {
+		index[strings.TrimSpace(name)] = i
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			skipped++
+			continue
+		}
+
+		product, ok := offProductFromFields(func(name string) (interface{}, bool) {
+			i, ok := index[name]
+			if !ok || i >= len(record) || record[i] == "" {
+				return nil, false
+			}
+			return record[i], true
+		})
+		if !ok {
+			skipped++
+			continue
+		}
+		add(product)
+	}
+	return skipped, nil
+}
+
+// applyOFFProduct fills a food's calories and nutrients from a product. A
+// priced food keeps its pack size, since the price was for that pack;
+// otherwise the product's pack size is used.
+func applyOFFProduct(food *Food, product OFFProduct) {
+	if food.Name == "" {
+		food.Name = product.Name
+	}
+	food.Barcode = product.Barcode
+	priced := food.Price > 0 || len(food.PriceHistory) > 0
+	if product.PackGrams > 0 && !(priced && food.Quantity > 0) {
+		food.Quantity = product.PackGrams
+	}
+	if food.Quantity == 0 {
+		food.Quantity = 100
+	}
+	ratio := float64(food.Quantity) / 100
+	food.Calories = product.CaloriesPer100g * ratio
+	food.Nutrients = product.Nutrients.Scale(ratio)
+	updateFoodStats(food)
+}
+
+// countingReader counts the bytes read through it for progress reports
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// offKeepBarcodes are the barcodes an import always keeps: those of foods,
+// those already in the catalogue and those asked for
+func offKeepBarcodes(requested []string) map[string]bool {
+	keep := make(map[string]bool)
+	for _, food := range foods {
+		if code := normalizeBarcode(food.Barcode); code != "" {
+			keep[code] = true
+		}
+	}
+	for code := range offProducts {
+		keep[code] = true
+	}
+	for _, code := range requested {
+		keep[normalizeBarcode(code)] = true
+	}
+	return keep
+}
+
+// scanOpenFoodFacts streams a downloaded CSV or JSONL dump, keeping the
+// products in keep and up to room others. Reading the dump is the slow
+// part and touches no shared data, so only this step runs away from the UI;
+// the scan is applied with applyOFFScan back on it. Progress is reported
+// every few thousand products from the reading goroutine.
+func scanOpenFoodFacts(path string, keep map[string]bool, room int, progress func(offProgress)) (offScan, error) {
+	scan := offScan{Products: make(map[string]OFFProduct)}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return scan, fmt.Errorf("error opening dump: %v", err)
+	}
+	defer file.Close()
+
+	var total int64
+	if info, err := file.Stat(); err == nil {
+		total = info.Size()
+	}
+	counter := &countingReader{r: file}
+
+	const progressEvery = 5000
+	add := func(product OFFProduct) {
+		scan.Read++
+		if keep[product.Barcode] {
+			scan.Products[product.Barcode] = product
+		} else if room > 0 {
+			if _, seen := scan.Products[product.Barcode]; !seen {
+				room--
+			}
+			scan.Products[product.Barcode] = product
+		}
+		if progress != nil && scan.Read%progressEvery == 0 {
+			progress(offProgress{BytesRead: counter.n, BytesTotal: total, Products: scan.Read})
+		}
+	}
+
+	lower := strings.ToLower(path)
+	if strings.HasSuffix(lower, ".jsonl") || strings.HasSuffix(lower, ".json") {
+		scan.Skipped, err = readOFFJSONL(counter, add)
+	} else {
+		scan.Skipped, err = readOFFCSV(counter, add)
+	}
+	if progress != nil {
+		progress(offProgress{BytesRead: counter.n, BytesTotal: total, Products: scan.Read})
+	}
+	return scan, err
+}
+
+// applyOFFScan adds scanned products to the catalogue, updates foods with
+// matching barcodes and creates foods (with no price) for the create
+// barcodes that have none yet
+func applyOFFScan(scan offScan, create []string) (offImportResult, error) {
+	result := offImportResult{Products: scan.Read, Kept: len(scan.Products), Skipped: scan.Skipped}
+
+	for code, product := range scan.Products {
+		offProducts[code] = product
+		if food := getFoodByBarcode(code); food != nil {
+			applyOFFProduct(food, product)
+			result.Updated++
+		}
+	}
+
+	for _, code := range create {
+		code = normalizeBarcode(code)
+		product, ok := scan.Products[code]
+		if !ok {
+			result.NotFound = append(result.NotFound, code)
+			continue
+		}
+		if getFoodByBarcode(code) != nil {
+			continue
+		}
+		food := Food{ID: nextFoodID()}
+		applyOFFProduct(&food, product)
+		foods = append(foods, food)
+		result.Created++
+	}
+
+	if err := saveOFFProducts(); err != nil {
+		return result, err
+	}
+	if result.Updated > 0 || result.Created > 0 {
+		refreshRecipes()
+		if err := saveToFile(); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// importOpenFoodFacts reads a dump into the local product catalogue, updates
+// foods with matching barcodes and creates foods for the create barcodes
+func importOpenFoodFacts(path string, create []string, progress func(offProgress)) (offImportResult, error) {
+	if !offProductsLoaded {
+		if err := loadOFFProducts(); err != nil {
+			return offImportResult{}, err
+		}
+	}
+	scan, err := scanOpenFoodFacts(path, offKeepBarcodes(create), offCatalogueLimit-len(offProducts), progress)
+	if err != nil {
+		return offImportResult{}, err
+	}
+	return applyOFFScan(scan, create)
+}
+
+func showImportWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Import Open Food Facts")
+
+	pathEntry := widget.NewEntry()
+	pathEntry.SetPlaceHolder("Path to .csv or .jsonl dump")
+
+	browseBtn := widget.NewButton("Browse", func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+			pathEntry.SetText(reader.URI().Path())
+		}, window)
+	})
+
+	createEntry := widget.NewMultiLineEntry()
+	createEntry.SetPlaceHolder("Barcodes to add as new foods, one per line (optional)")
+
+	progress := widget.NewProgressBar()
+	progress.Hide()
+	resultLabel := widget.NewLabel("Foods with a matching barcode are updated with the product's\ncalories per 100g and nutrients. Unpriced foods also take its pack size.")
+
+	var importBtn *widget.Button
+	importBtn = widget.NewButton("Import", func() {
+		path := strings.TrimSpace(pathEntry.Text)
+		if path == "" {
+			resultLabel.SetText("Please choose a dump file")
+			return
+		}
+
+		var create []string
+		for _, line := range strings.Split(createEntry.Text, "\n") {
+			code := strings.TrimSpace(line)
+			if code == "" {
+				continue
+			}
+			if !validBarcode(code) {
+				resultLabel.SetText(fmt.Sprintf("%s is not a valid barcode", code))
+				return
+			}
+			create = append(create, code)
+		}
+
+		if !offProductsLoaded {
+			if err := loadOFFProducts(); err != nil {
+				resultLabel.SetText("Import failed: " + err.Error())
+				return
+			}
+		}
+		keep := offKeepBarcodes(create)
+		room := offCatalogueLimit - len(offProducts)
+
+		// Dumps run to gigabytes, so they are read in the background and only
+		// the products needed are kept
+		importBtn.Disable()
+		progress.SetValue(0)
+		progress.Show()
+		resultLabel.SetText("Reading dump...")
+		go func() {
+			scan, err := scanOpenFoodFacts(path, keep, room, func(p offProgress) {
+				fyne.Do(func() {
+					if p.BytesTotal > 0 {
+						progress.SetValue(float64(p.BytesRead) / float64(p.BytesTotal))
+					}
+					resultLabel.SetText(fmt.Sprintf("Reading dump... %d products", p.Products))
+				})
+			})
+
+			// Foods and the catalogue are shared with the rest of the UI, so
+			// the scan is applied on the UI goroutine
+			fyne.Do(func() {
+				defer importBtn.Enable()
+				if err != nil {
+					resultLabel.SetText("Import failed: " + err.Error())
+					return
+				}
+
+				result, err := applyOFFScan(scan, create)
+				if err != nil {
+					resultLabel.SetText("Import failed: " + err.Error())
+					return
+				}
+				text := fmt.Sprintf("Read %d products (%d skipped), kept %d\nUpdated %d foods, created %d foods",
+					result.Products, result.Skipped, result.Kept, result.Updated, result.Created)
+				if len(result.NotFound) > 0 {
+					text += "\nNot in the dump: " + strings.Join(result.NotFound, ", ")
+				}
+				progress.SetValue(1)
+				resultLabel.SetText(text)
+			})
+		}()
+	})
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	content := container.NewVBox(
+		widget.NewLabel("Import Open Food Facts"),
+		container.NewBorder(nil, nil, nil, browseBtn, pathEntry),
+		createEntry,
+		importBtn,
+		progress,
+		resultLabel,
+		backBtn,
+	)
+
+	window.SetContent(content)
+	window.Resize(fyne.NewSize(450, 400))
+	window.Show()
+	return window
+}
@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestNormalizeBarcode(t *testing.T) {
+	tests := []struct {
+		code, want string
+	}{
+		{"4006381333931", "4006381333931"},   // EAN-13
+		{"036000291452", "0036000291452"},    // UPC-A becomes EAN-13
+		{"0 36000 29145 2", "0036000291452"}, // spaces as printed
+		{"96385074", "96385074"},             // EAN-8
+		{"4006-3813-3393-1", "4006381333931"},
+		{"abc", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeBarcode(tt.code); got != tt.want {
+			t.Errorf("normalizeBarcode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestValidBarcode(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"4006381333931", true},   // EAN-13
+		{"4006381333932", false},  // EAN-13, bad check digit
+		{"036000291452", true},    // UPC-A
+		{"036000291453", false},   // UPC-A, bad check digit
+		{"96385074", true},        // EAN-8
+		{"96385075", false},       // EAN-8, bad check digit
+		{"10036000291459", true},  // GTIN-14
+		{"10036000291458", false}, // GTIN-14, bad check digit
+		{"1234567", false},        // wrong length
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := validBarcode(tt.code); got != tt.want {
+			t.Errorf("validBarcode(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const pantryFile = "pantry_data.json"
+
+// Purchase is food bought into the pantry: whole packs of the food's
+// Quantity plus any loose grams
+type Purchase struct {
+	ID     int    `json:"id"`
+	Date   string `json:"date"` // YYYY-MM-DD
+	FoodID int    `json:"food_id"`
+	Packs  int    `json:"packs"`
+	Grams  int    `json:"grams"` // total grams bought, packs included
+}
+
+var purchases []Purchase
+
+// Days of recent eating used to judge whether stock is running low
+const (
+	pantryUsageDays = 28
+	pantryLowDays   = 7
+)
+
+// Save and load functions for the pantry
+func savePantryToFile() error {
+	file, err := os.Create(pantryFile)
+	if err != nil {
+		return fmt.Errorf("error creating pantry file: %v", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(purchases); err != nil {
+		return fmt.Errorf("error encoding pantry data: %v", err)
+	}
+	return nil
+}
+
+func loadPantryFromFile() error {
+	file, err := os.Open(pantryFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			purchases = make([]Purchase, 0)
+			return nil
+		}
+		return fmt.Errorf("error opening pantry file: %v", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	if err := decoder.Decode(&purchases); err != nil {
+		return fmt.Errorf("error decoding pantry data: %v", err)
+	}
+	return nil
+}
+
+func nextPurchaseID() int {
+	maxID := 0
+	for _, p := range purchases {
+		if p.ID > maxID {
+			maxID = p.ID
+		}
+	}
+	return maxID + 1
+}
+
+// pantryStock works out the grams of each purchased food left on hand.
+// Purchases add stock and diary entries from the first purchase on draw it
+// down, so editing or deleting an entry puts its stock back. Recipes draw
+// down their ingredients. Stock never goes below zero: food eaten with none
+// on hand came from elsewhere.
+func pantryStock() map[int]float64 {
+	type stockChange struct {
+		date     string
+		purchase bool
+		foodID   int
+		grams    float64
+	}
+
+	firstPurchase := make(map[int]string)
+	var changes []stockChange
+	for _, p := range purchases {
+		if first, ok := firstPurchase[p.FoodID]; !ok || p.Date < first {
+			firstPurchase[p.FoodID] = p.Date
+		}
+		changes = append(changes, stockChange{date: p.Date, purchase: true, foodID: p.FoodID, grams: float64(p.Grams)})
+	}
+	for _, entry := range dailyDiary.Entries {
+		for foodID, grams := range rawIngredients(entry.FoodID, float64(entry.Quantity)) {
+			first, ok := firstPurchase[foodID]
+			if !ok || entry.Date < first {
+				continue
+			}
+			changes = append(changes, stockChange{date: entry.Date, foodID: foodID, grams: -grams})
+		}
+	}
+
+	// Food bought on a day is on hand for that day's meals
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].date != changes[j].date {
+			return changes[i].date < changes[j].date
+		}
+		return changes[i].purchase && !changes[j].purchase
+	})
+
+	stock := make(map[int]float64)
+	for _, c := range changes {
+		stock[c.foodID] = math.Max(0, stock[c.foodID]+c.grams)
+	}
+	return stock
+}
+
+// recentDailyUse is the average grams of each food eaten per day over the
+// last pantryUsageDays, with recipes counted as their ingredients
+func recentDailyUse(today time.Time) map[int]float64 {
+	since := today.AddDate(0, 0, -pantryUsageDays).Format("2006-01-02")
+	use := make(map[int]float64)
+	for _, entry := range dailyDiary.Entries {
+		if entry.Date > since {
+			for foodID, grams := range rawIngredients(entry.FoodID, float64(entry.Quantity)) {
+				use[foodID] += grams / pantryUsageDays
+			}
+		}
+	}
+	return use
+}
+
+// pantryItem is one food's line in the pantry
+type pantryItem struct {
+	food  Food
+	grams float64
+	value float64
+	low   bool
+}
+
+// pantryItems lists every purchased food with its stock, value at the
+// food's current price, and whether it is running low: under a week of
+// recent eating, or under a quarter pack for foods not eaten lately
+func pantryItems() []pantryItem {
+	stock := pantryStock()
+	use := recentDailyUse(time.Now())
+
+	var items []pantryItem
+	for _, food := range foods {
+		grams, ok := stock[food.ID]
+		if !ok {
+			continue
+		}
+
+		item := pantryItem{food: food, grams: grams}
+		if food.Quantity > 0 {
+			item.value = grams / float64(food.Quantity) * food.Price
+		}
+		if daily := use[food.ID]; daily > 0 {
+			item.low = grams < daily*pantryLowDays
+		} else {
+			item.low = grams < float64(food.Quantity)/4
+		}
+		items = append(items, item)
+	}
+
+	// Low items first, then by name
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].low != items[j].low {
+			return items[i].low
+		}
+		return strings.ToLower(items[i].food.Name) < strings.ToLower(items[j].food.Name)
+	})
+	return items
+}
+
+func (item pantryItem) label() string {
+	text := fmt.Sprintf("%s: %.0fg", item.food.Name, item.grams)
+	if item.food.Quantity > 0 {
+		text += fmt.Sprintf(" (%.1f packs)", item.grams/float64(item.food.Quantity))
+	}
+	text += fmt.Sprintf(" - $%.2f", item.value)
+	if item.low {
+		text += "  ⚠ running low"
+	}
+	return text
+}
+
+func showPantryWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Pantry")
+
+	foodOptions := make([]string, 0, len(foods))
+	for _, food := range foods {
+		foodOptions = append(foodOptions, foodOptionLabel(food))
+	}
+	foodSelect := widget.NewSelect(foodOptions, nil)
+	foodSelect.PlaceHolder = "Choose a food"
+
+	dateEntry := widget.NewEntry()
+	dateEntry.SetText(time.Now().Format("2006-01-02"))
+	calendarBtn := widget.NewButton("📅", func() {
+		initial, err := time.Parse("2006-01-02", dateEntry.Text)
+		if err != nil {
+			initial = time.Now()
+		}
+		showDatePicker(window, initial, func(picked time.Time) {
+			dateEntry.SetText(picked.Format("2006-01-02"))
+		})
+	})
+
+	packsEntry := widget.NewEntry()
+	packsEntry.SetPlaceHolder("Number of packs")
+	gramsEntry := widget.NewEntry()
+	gramsEntry.SetPlaceHolder("Extra loose grams (optional)")
+	priceEntry := widget.NewEntry()
+	priceEntry.SetPlaceHolder("Price per pack (optional)")
+
+	totalLabel := widget.NewLabel("")
+	statusLabel := widget.NewLabel("")
+
+	var items []pantryItem
+	stockList := widget.NewList(
+		func() int { return len(items) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(items[id].label())
+		},
+	)
+
+	// Newest purchases first
+	var recent []Purchase
+	purchaseList := widget.NewList(
+		func() int { return len(recent) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			p := recent[id]
+			name := fmt.Sprintf("Food #%d", p.FoodID)
+			if food := getFoodByID(p.FoodID); food != nil {
+				name = food.Name
+			}
+			item.(*widget.Label).SetText(fmt.Sprintf("%s: %s, %d packs, %dg", p.Date, name, p.Packs, p.Grams))
+		},
+	)
+
+	refresh := func() {
+		items = pantryItems()
+		var total float64
+		lowCount := 0
+		for _, item := range items {
+			total += item.value
+			if item.low {
+				lowCount++
+			}
+		}
+		totalLabel.SetText(fmt.Sprintf("Stock value: $%.2f, %d items running low", total, lowCount))
+		stockList.Refresh()
+
+		recent = append([]Purchase(nil), purchases...)
+		sort.SliceStable(recent, func(i, j int) bool {
+			return recent[i].Date > recent[j].Date
+		})
+		purchaseList.UnselectAll()
+		purchaseList.Refresh()
+	}
+
+	purchaseList.OnSelected = func(id widget.ListItemID) {
+		p := recent[id]
+		dialog.ShowConfirm("Remove Purchase",
+			fmt.Sprintf("Remove the purchase of %dg on %s?", p.Grams, p.Date),
+			func(ok bool) {
+				if ok {
+					newPurchases := make([]Purchase, 0, len(purchases))
+					for _, other := range purchases {
+						if other.ID != p.ID {
+							newPurchases = append(newPurchases, other)
+						}
+					}
+					purchases = newPurchases
+					if err := savePantryToFile(); err != nil {
+						statusLabel.SetText("Error saving pantry: " + err.Error())
+					}
+				}
+				refresh()
+			}, window)
+	}
+
+	addBtn := widget.NewButton("Add Purchase", func() {
+		var food *Food
+		for i := range foods {
+			if foodOptionLabel(foods[i]) == foodSelect.Selected {
+				food = &foods[i]
+				break
+			}
+		}
+		if food == nil {
+			statusLabel.SetText("Please choose a food")
+			return
+		}
+
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(dateEntry.Text))
+		if err != nil {
+			statusLabel.SetText("Please enter the date as YYYY-MM-DD")
+			return
+		}
+
+		packs := 0
+		if text := strings.TrimSpace(packsEntry.Text); text != "" {
+			packs, err = strconv.Atoi(text)
+			if err != nil || packs < 0 {
+				statusLabel.SetText("Invalid number of packs")
+				return
+			}
+		}
+		loose := 0
+		if text := strings.TrimSpace(gramsEntry.Text); text != "" {
+			loose, err = strconv.Atoi(text)
+			if err != nil || loose < 0 {
+				statusLabel.SetText("Invalid grams. Please enter a whole number")
+				return
+			}
+		}
+		grams := packs*food.Quantity + loose
+		if grams <= 0 {
+			statusLabel.SetText("Please enter packs or grams bought")
+			return
+		}
+
+		// A price paid also goes into the food's price history
+		if text := strings.TrimSpace(priceEntry.Text); text != "" {
+			price, err := validateCurrency(text)
+			if err != nil || price <= 0 {
+				statusLabel.SetText("Invalid price. Please enter an amount")
+				return
+			}
+			recordPrice(food, date.Format("2006-01-02"), price)
+			refreshRecipes()
+			if err := saveToFile(); err != nil {
+				statusLabel.SetText("Error saving food: " + err.Error())
+				return
+			}
+		}
+
+		purchases = append(purchases, Purchase{
+			ID:     nextPurchaseID(),
+			Date:   date.Format("2006-01-02"),
+			FoodID: food.ID,
+			Packs:  packs,
+			Grams:  grams,
+		})
+		if err := savePantryToFile(); err != nil {
+			statusLabel.SetText("Error saving pantry: " + err.Error())
+			return
+		}
+
+		statusLabel.SetText(fmt.Sprintf("Added %dg of %s", grams, food.Name))
+		packsEntry.SetText("")
+		gramsEntry.SetText("")
+		priceEntry.SetText("")
+		refresh()
+	})
+
+	// Stock follows the diary as food is eaten
+	removeListener := addDiaryListener(refresh)
+	window.SetOnClosed(removeListener)
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	purchaseForm := widget.NewForm(
+		widget.NewFormItem("Food", foodSelect),
+		widget.NewFormItem("Date", container.NewBorder(nil, nil, nil, calendarBtn, dateEntry)),
+		widget.NewFormItem("Packs", packsEntry),
+		widget.NewFormItem("Grams", gramsEntry),
+		widget.NewFormItem("Price", priceEntry),
+	)
+
+	stockScroll := container.NewScroll(stockList)
+	stockScroll.SetMinSize(fyne.NewSize(460, 220))
+	purchaseScroll := container.NewScroll(purchaseList)
+	purchaseScroll.SetMinSize(fyne.NewSize(460, 120))
+
+	content := container.NewVBox(
+		widget.NewLabel("On Hand"),
+		totalLabel,
+		stockScroll,
+		widget.NewSeparator(),
+		widget.NewLabel("Record a Purchase"),
+		purchaseForm,
+		addBtn,
+		statusLabel,
+		widget.NewLabel("Purchases (tap to remove)"),
+		purchaseScroll,
+		backBtn,
+	)
+
+	refresh()
+	window.SetContent(container.NewVScroll(content))
+	window.Resize(fyne.NewSize(500, 750))
+	window.Show()
+	return window
+}
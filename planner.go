@@ -0,0 +1,385 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const planFile = "plan_data.json"
+
+// mealPlan holds planned diary entries. They are kept apart from the diary
+// so totals, goals and stats only ever count food actually eaten.
+var mealPlan []DiaryEntry
+
+// Save and load functions for the meal plan
+func savePlanToFile() error {
+	file, err := os.Create(planFile)
+	if err != nil {
+		return fmt.Errorf("error creating meal plan file: %v", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(mealPlan); err != nil {
+		return fmt.Errorf("error encoding meal plan data: %v", err)
+	}
+	return nil
+}
+
+func loadPlanFromFile() error {
+	file, err := os.Open(planFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			mealPlan = make([]DiaryEntry, 0)
+			return nil
+		}
+		return fmt.Errorf("error opening meal plan file: %v", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	if err := decoder.Decode(&mealPlan); err != nil {
+		return fmt.Errorf("error decoding meal plan data: %v", err)
+	}
+	return nil
+}
+
+func nextPlanEntryID() int {
+	maxID := 0
+	for _, entry := range mealPlan {
+		if entry.ID > maxID {
+			maxID = entry.ID
+		}
+	}
+	return maxID + 1
+}
+
+func getPlanEntryIndex(id int) int {
+	for i, entry := range mealPlan {
+		if entry.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// isLogged reports whether a planned item has become a diary entry that
+// still exists. The entry may since have been edited to another food or
+// day; it still stands for the planned item.
+func (e DiaryEntry) isLogged() bool {
+	return e.LoggedID != 0 && getDiaryEntryIndex(e.LoggedID) >= 0
+}
+
+// planFood adds a planned item, priced at the food's price for that date
+func planFood(food Food, amount float64, unit, date string, meal MealSlot) (DiaryEntry, error) {
+	entry, err := newDiaryEntryInUnit(food, amount, unit, date)
+	if err != nil {
+		return DiaryEntry{}, err
+	}
+	entry.ID = nextPlanEntryID()
+	entry.Meal = meal
+	entry.Planned = true
+
+	mealPlan = append(mealPlan, entry)
+	if err := savePlanToFile(); err != nil {
+		return DiaryEntry{}, err
+	}
+	return entry, nil
+}
+
+func removePlanEntry(id int) error {
+	idx := getPlanEntryIndex(id)
+	if idx < 0 {
+		return fmt.Errorf("planned item %d not found", id)
+	}
+	mealPlan = append(mealPlan[:idx], mealPlan[idx+1:]...)
+	return savePlanToFile()
+}
+
+// logPlanEntry turns a planned item into a real diary entry on its planned
+// date, priced as of that date, and links the two
+func logPlanEntry(id int) (DiaryEntry, error) {
+	idx := getPlanEntryIndex(id)
+	if idx < 0 {
+		return DiaryEntry{}, fmt.Errorf("planned item %d not found", id)
+	}
+	planned := mealPlan[idx]
+	if planned.isLogged() {
+		return DiaryEntry{}, fmt.Errorf("%s has already been logged", planned.FoodName)
+	}
+	if planned.Date > time.Now().Format("2006-01-02") {
+		return DiaryEntry{}, fmt.Errorf("cannot log food for a future date")
+	}
+
+	food := getFoodByID(planned.FoodID)
+	if food == nil {
+		return DiaryEntry{}, fmt.Errorf("%s is no longer in the food database", planned.FoodName)
+	}
+
+	entry := recomputeDiaryEntry(planned, *food)
+	entry.ID = nextDiaryEntryID()
+	entry.Planned = false
+	entry.LoggedID = 0
+
+	dailyDiary.Entries = append(dailyDiary.Entries, entry)
+	if err := saveDiaryToFile(); err != nil {
+		return DiaryEntry{}, err
+	}
+	mealPlan[idx].LoggedID = entry.ID
+	if err := savePlanToFile(); err != nil {
+		return DiaryEntry{}, err
+	}
+	notifyDiaryChanged()
+	return entry, nil
+}
+
+// weekStart is the Monday on or before day
+func weekStart(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return time.Date(day.Year(), day.Month(), day.Day()-offset, 0, 0, 0, 0, time.Local)
+}
+
+// dayComparison is one day's planned and logged totals
+type dayComparison struct {
+	date                         string
+	plannedCalories, plannedCost float64
+	loggedCalories, loggedCost   float64
+	plannedItems, loggedPlanned  int
+}
+
+// compareWeek totals the plan and the diary for the seven days from start
+func compareWeek(start time.Time) []dayComparison {
+	days := make([]dayComparison, 7)
+	index := make(map[string]int)
+	for i := range days {
+		days[i].date = start.AddDate(0, 0, i).Format("2006-01-02")
+		index[days[i].date] = i
+	}
+
+	for _, entry := range mealPlan {
+		if i, ok := index[entry.Date]; ok {
+			days[i].plannedCalories += entry.Calories
+			days[i].plannedCost += entry.Cost
+			days[i].plannedItems++
+			if entry.isLogged() {
+				days[i].loggedPlanned++
+			}
+		}
+	}
+	for _, entry := range dailyDiary.Entries {
+		if i, ok := index[entry.Date]; ok {
+			days[i].loggedCalories += entry.Calories
+			days[i].loggedCost += entry.Cost
+		}
+	}
+	return days
+}
+
+// planReport describes planned against logged calories and spending for a
+// week, skipping days still to come
+func planReport(start time.Time) string {
+	today := time.Now().Format("2006-01-02")
+
+	var report strings.Builder
+	var planCal, planCost, logCal, logCost float64
+	for _, day := range compareWeek(start) {
+		if day.date > today {
+			continue
+		}
+		date, _ := time.Parse("2006-01-02", day.date)
+		report.WriteString(fmt.Sprintf("%s: planned %.0f cal $%.2f, logged %.0f cal $%.2f (%+.0f cal, $%+.2f), %d of %d planned items eaten\n",
+			date.Format("Mon 02 Jan"), day.plannedCalories, day.plannedCost, day.loggedCalories, day.loggedCost,
+			day.loggedCalories-day.plannedCalories, day.loggedCost-day.plannedCost, day.loggedPlanned, day.plannedItems))
+		planCal += day.plannedCalories
+		planCost += day.plannedCost
+		logCal += day.loggedCalories
+		logCost += day.loggedCost
+	}
+
+	if report.Len() == 0 {
+		return "This week has not started yet"
+	}
+	report.WriteString(fmt.Sprintf("\nWeek so far: planned %.0f cal $%.2f, logged %.0f cal $%.2f (%+.0f cal, $%+.2f)",
+		planCal, planCost, logCal, logCost, logCal-planCal, logCost-planCost))
+	return report.String()
+}
+
+// showPlanFoodDialog asks for a food, amount and meal to plan on date
+func showPlanFoodDialog(parent fyne.Window, date string, onPlanned func()) {
+	foodOptions := make([]string, 0, len(foods))
+	for _, food := range foods {
+		foodOptions = append(foodOptions, foodOptionLabel(food))
+	}
+
+	quantityEntry := widget.NewEntry()
+	quantityEntry.SetPlaceHolder("Amount")
+	unitSelect := widget.NewSelect([]string{unitGrams}, nil)
+	unitSelect.SetSelected(unitGrams)
+
+	var selectedFood *Food
+	foodSelect := widget.NewSelect(foodOptions, func(option string) {
+		for i := range foods {
+			if foodOptionLabel(foods[i]) == option {
+				selectedFood = &foods[i]
+				unitSelect.Options = foods[i].unitNames()
+				unitSelect.SetSelected(unitGrams)
+				unitSelect.Refresh()
+				break
+			}
+		}
+	})
+	foodSelect.PlaceHolder = "Choose a food"
+
+	mealOptions := make([]string, 0, len(mealSlots))
+	for _, meal := range mealSlots {
+		mealOptions = append(mealOptions, meal.Label())
+	}
+	mealSelect := widget.NewSelect(mealOptions, nil)
+	mealSelect.SetSelected(Breakfast.Label())
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Food", foodSelect),
+		widget.NewFormItem("Quantity", container.NewBorder(nil, nil, nil, unitSelect, quantityEntry)),
+		widget.NewFormItem("Meal", mealSelect),
+	}
+
+	dialog.ShowForm("Plan Food for "+date, "Plan", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if selectedFood == nil {
+			dialog.ShowError(fmt.Errorf("please choose a food"), parent)
+			return
+		}
+		amount, err := strconv.ParseFloat(strings.TrimSpace(quantityEntry.Text), 64)
+		if err != nil || amount <= 0 {
+			dialog.ShowError(fmt.Errorf("please enter a valid quantity"), parent)
+			return
+		}
+		if _, err := planFood(*selectedFood, amount, unitSelect.Selected, date, mealSlotFromLabel(mealSelect.Selected)); err != nil {
+			dialog.ShowError(err, parent)
+			return
+		}
+		onPlanned()
+	}, parent)
+}
+
+func showMealPlanWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Meal Planner")
+
+	start := weekStart(time.Now())
+
+	weekLabel := widget.NewLabelWithStyle("", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	daysBox := container.NewVBox()
+	reportLabel := widget.NewLabel("")
+
+	var refresh func()
+	refresh = func() {
+		weekLabel.SetText(fmt.Sprintf("Week of %s", start.Format("Mon 02 Jan 2006")))
+		today := time.Now().Format("2006-01-02")
+
+		// Group the week's planned items by day
+		byDate := make(map[string][]DiaryEntry)
+		for _, entry := range mealPlan {
+			byDate[entry.Date] = append(byDate[entry.Date], entry)
+		}
+
+		daysBox.Objects = nil
+		for _, day := range compareWeek(start) {
+			date, _ := time.Parse("2006-01-02", day.date)
+			heading := fmt.Sprintf("%s: %.0f cal, $%.2f planned", date.Format("Mon 02 Jan"), day.plannedCalories, day.plannedCost)
+			if goal, ok := goalForDate(day.date); ok {
+				heading += fmt.Sprintf(" (goal %.0f cal)", goal.Calories)
+			}
+
+			planDate := day.date
+			planBtn := widget.NewButton("+ Plan", func() {
+				showPlanFoodDialog(window, planDate, refresh)
+			})
+			daysBox.Add(container.NewBorder(nil, nil, nil, planBtn,
+				widget.NewLabelWithStyle(heading, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})))
+
+			var entries []DiaryEntry
+			for _, meal := range mealSlots {
+				for _, entry := range byDate[day.date] {
+					if entry.Meal == meal {
+						entries = append(entries, entry)
+					}
+				}
+			}
+			for _, entry := range entries {
+				planned := entry
+				label := widget.NewLabel(fmt.Sprintf("  %s: %s %s - %.0f cal, $%.2f",
+					planned.Meal.Label(), planned.FoodName, planned.amountLabel(), planned.Calories, planned.Cost))
+
+				eatenBtn := widget.NewButton("Eaten", func() {
+					if _, err := logPlanEntry(planned.ID); err != nil {
+						dialog.ShowError(err, window)
+						return
+					}
+					refresh()
+				})
+				if planned.isLogged() {
+					eatenBtn.SetText("✓ Logged")
+					eatenBtn.Disable()
+				} else if planned.Date > today {
+					eatenBtn.Disable()
+				}
+
+				removeBtn := widget.NewButton("✕", func() {
+					if err := removePlanEntry(planned.ID); err != nil {
+						dialog.ShowError(err, window)
+						return
+					}
+					refresh()
+				})
+
+				daysBox.Add(container.NewBorder(nil, nil, nil, container.NewHBox(eatenBtn, removeBtn), label))
+			}
+			daysBox.Add(widget.NewSeparator())
+		}
+		daysBox.Refresh()
+
+		reportLabel.SetText(planReport(start))
+	}
+
+	prevBtn := widget.NewButton("< Previous Week", func() {
+		start = start.AddDate(0, 0, -7)
+		refresh()
+	})
+	nextBtn := widget.NewButton("Next Week >", func() {
+		start = start.AddDate(0, 0, 7)
+		refresh()
+	})
+
+	// Logged amounts change as the diary is edited
+	removeListener := addDiaryListener(refresh)
+	window.SetOnClosed(removeListener)
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	content := container.NewVBox(
+		container.NewBorder(nil, nil, prevBtn, nextBtn, weekLabel),
+		daysBox,
+		widget.NewLabelWithStyle("Planned vs Actual", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		reportLabel,
+		backBtn,
+	)
+
+	refresh()
+	window.SetContent(container.NewVScroll(content))
+	window.Resize(fyne.NewSize(650, 750))
+	window.Show()
+	return window
+}
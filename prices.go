@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PricePoint is what a food's pack (Food.Quantity grams) cost from Date on
+type PricePoint struct {
+	Date  string  `json:"date"` // YYYY-MM-DD
+	Price float64 `json:"price"`
+}
+
+// priceOn returns the pack price in effect on a date (YYYY-MM-DD). Dates
+// before the first recorded price use the earliest one, and foods without a
+// history use their current Price.
+func (f Food) priceOn(date string) float64 {
+	if len(f.PriceHistory) == 0 {
+		return f.Price
+	}
+
+	price := f.PriceHistory[0].Price
+	for _, p := range f.PriceHistory {
+		if p.Date > date {
+			break
+		}
+		price = p.Price
+	}
+	return price
+}
+
+// firstLoggedDate is the earliest diary date a food was logged on, or ""
+func firstLoggedDate(foodID int) string {
+	first := ""
+	for _, entry := range dailyDiary.Entries {
+		if entry.FoodID == foodID && (first == "" || entry.Date < first) {
+			first = entry.Date
+		}
+	}
+	return first
+}
+
+// recordPrice adds a dated price point, replacing any on the same date, and
+// sets the food's current Price to the one in effect today. A price entered
+// for a later date takes over once that date comes.
+func recordPrice(food *Food, date string, price float64) {
+	history := make([]PricePoint, 0, len(food.PriceHistory)+2)
+	history = append(history, food.PriceHistory...)
+
+	// Foods from before price history existed keep their old price for the
+	// entries already logged against it
+	if len(history) == 0 && food.Price > 0 && food.Price != price {
+		if first := firstLoggedDate(food.ID); first != "" && first < date {
+			history = append(history, PricePoint{Date: first, Price: food.Price})
+		}
+	}
+
+	replaced := false
+	for i := range history {
+		if history[i].Date == date {
+			history[i].Price = price
+			replaced = true
+		}
+	}
+	if !replaced {
+		history = append(history, PricePoint{Date: date, Price: price})
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Date < history[j].Date
+	})
+
+	food.PriceHistory = history
+	food.Price = food.priceOn(time.Now().Format("2006-01-02"))
+	updateFoodStats(food)
+}
+
+// applyCurrentPrices brings every food's Price up to date with its history,
+// for prices that were recorded ahead of time, and reports whether any
+// changed
+func applyCurrentPrices() bool {
+	today := time.Now().Format("2006-01-02")
+	changed := false
+	for i := range foods {
+		if len(foods[i].PriceHistory) == 0 {
+			continue
+		}
+		if price := foods[i].priceOn(today); price != foods[i].Price {
+			foods[i].Price = price
+			updateFoodStats(&foods[i])
+			changed = true
+		}
+	}
+	return changed
+}
+
+func showPriceHistoryWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Price History")
+
+	foodOptions := make([]string, 0, len(foods))
+	for _, food := range foods {
+		foodOptions = append(foodOptions, foodOptionLabel(food))
+	}
+
+	historyLabel := widget.NewLabel("")
+	priceChart := container.NewMax()
+	calPerDollarChart := container.NewMax()
+	statusLabel := widget.NewLabel("")
+
+	dateEntry := widget.NewEntry()
+	dateEntry.SetText(time.Now().Format("2006-01-02"))
+	calendarBtn := widget.NewButton("📅", func() {
+		initial, err := time.Parse("2006-01-02", dateEntry.Text)
+		if err != nil {
+			initial = time.Now()
+		}
+		showDatePicker(window, initial, func(picked time.Time) {
+			dateEntry.SetText(picked.Format("2006-01-02"))
+		})
+	})
+
+	priceEntry := widget.NewEntry()
+	priceEntry.SetPlaceHolder("Price paid ($)")
+
+	selectedID := 0
+
+	showHistory := func() {
+		food := getFoodByID(selectedID)
+		if food == nil {
+			return
+		}
+
+		history := food.PriceHistory
+		if len(history) == 0 {
+			history = []PricePoint{{Date: time.Now().Format("2006-01-02"), Price: food.Price}}
+		}
+
+		var text strings.Builder
+		text.WriteString(fmt.Sprintf("%s, %dg pack, %.0f cal\n", food.Name, food.Quantity, food.Calories))
+
+		start, _ := time.Parse("2006-01-02", history[0].Date)
+		var pricePoints, calPerDollarPoints []chartPoint
+		for _, p := range history {
+			var calPerDollar float64
+			if p.Price > 0 {
+				calPerDollar = food.Calories / p.Price
+			}
+			text.WriteString(fmt.Sprintf("%s: $%.2f (%.0f cal/$)\n", p.Date, p.Price, calPerDollar))
+
+			date, _ := time.Parse("2006-01-02", p.Date)
+			x := date.Sub(start).Hours() / 24
+			pricePoints = append(pricePoints, chartPoint{X: x, Value: p.Price, Label: p.Date})
+			calPerDollarPoints = append(calPerDollarPoints, chartPoint{X: x, Value: calPerDollar, Label: p.Date})
+		}
+		historyLabel.SetText(text.String())
+
+		priceChart.Objects = []fyne.CanvasObject{newLineChart(pricePoints, fyne.NewSize(460, 160), "$%.2f")}
+		priceChart.Refresh()
+		calPerDollarChart.Objects = []fyne.CanvasObject{newLineChart(calPerDollarPoints, fyne.NewSize(460, 160), "%.0f")}
+		calPerDollarChart.Refresh()
+	}
+
+	foodSelect := widget.NewSelect(foodOptions, func(option string) {
+		for _, food := range foods {
+			if foodOptionLabel(food) == option {
+				selectedID = food.ID
+				break
+			}
+		}
+		statusLabel.SetText("")
+		showHistory()
+	})
+	foodSelect.PlaceHolder = "Choose a food"
+
+	recordBtn := widget.NewButton("Record Price", func() {
+		food := getFoodByID(selectedID)
+		if food == nil {
+			statusLabel.SetText("Please choose a food")
+			return
+		}
+
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(dateEntry.Text))
+		if err != nil {
+			statusLabel.SetText("Please enter the date as YYYY-MM-DD")
+			return
+		}
+
+		price, err := validateCurrency(priceEntry.Text)
+		if err != nil || price <= 0 {
+			statusLabel.SetText("Invalid price. Please enter an amount")
+			return
+		}
+
+		recordPrice(food, date.Format("2006-01-02"), price)
+		refreshRecipes()
+		if err := saveToFile(); err != nil {
+			statusLabel.SetText("Error saving food: " + err.Error())
+			return
+		}
+
+		statusLabel.SetText(fmt.Sprintf("Recorded $%.2f on %s", price, date.Format("2006-01-02")))
+		priceEntry.SetText("")
+		showHistory()
+	})
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	recordForm := widget.NewForm(
+		widget.NewFormItem("Date", container.NewBorder(nil, nil, nil, calendarBtn, dateEntry)),
+		widget.NewFormItem("Price", priceEntry),
+	)
+
+	content := container.NewVBox(
+		widget.NewLabel("Price History"),
+		foodSelect,
+		historyLabel,
+		widget.NewLabel("Price per pack"),
+		priceChart,
+		widget.NewLabel("Calories per dollar"),
+		calPerDollarChart,
+		widget.NewSeparator(),
+		recordForm,
+		recordBtn,
+		statusLabel,
+		backBtn,
+	)
+
+	window.SetContent(container.NewVScroll(content))
+	window.Resize(fyne.NewSize(500, 700))
+	window.Show()
+	return window
+}
@@ -0,0 +1,505 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const recipeFile = "recipes_data.json"
+
+type RecipeIngredient struct {
+	FoodID int `json:"food_id"`
+	Grams  int `json:"grams"`
+}
+
+// Recipe is logged through a matching Food (FoodID) so the diary can treat it
+// like any other food. Its price and calories are always derived from the
+// ingredients.
+type Recipe struct {
+	ID           int                `json:"id"`
+	Name         string             `json:"name"`
+	FoodID       int                `json:"food_id"`
+	Ingredients  []RecipeIngredient `json:"ingredients"`
+	Servings     int                `json:"servings,omitempty"`
+	CookedWeight int                `json:"cooked_weight,omitempty"` // in grams
+}
+
+var recipes []Recipe
+
+// Save and load functions for recipes
+func saveRecipesToFile() error {
+	file, err := os.Create(recipeFile)
+	if err != nil {
+		return fmt.Errorf("error creating recipe file: %v", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(recipes); err != nil {
+		return fmt.Errorf("error encoding recipe data: %v", err)
+	}
+	return nil
+}
+
+func loadRecipesFromFile() error {
+	file, err := os.Open(recipeFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			recipes = make([]Recipe, 0)
+			return nil
+		}
+		return fmt.Errorf("error opening recipe file: %v", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	if err := decoder.Decode(&recipes); err != nil {
+		return fmt.Errorf("error decoding recipe data: %v", err)
+	}
+	return nil
+}
+
+// recipeTotals adds up the price, calories, nutrients and raw weight of the
+// ingredients
+func recipeTotals(ingredients []RecipeIngredient) (price, calories float64, nutrients Nutrients, grams int) {
+	for _, ing := range ingredients {
+		food := getFoodByID(ing.FoodID)
+		if food == nil || food.Quantity == 0 {
+			continue
+		}
+		ratio := float64(ing.Grams) / float64(food.Quantity)
+		price += food.Price * ratio
+		calories += food.Calories * ratio
+		nutrients = nutrients.Add(food.Nutrients.Scale(ratio))
+		grams += ing.Grams
+	}
+	return price, calories, nutrients, grams
+}
+
+// yieldGrams is the weight a whole recipe is logged against: the cooked
+// weight when known, otherwise the raw ingredient weight
+func (r Recipe) yieldGrams() int {
+	if r.CookedWeight > 0 {
+		return r.CookedWeight
+	}
+	_, _, _, grams := recipeTotals(r.Ingredients)
+	return grams
+}
+
+// recipeUses reports whether foodID is a recipe food with ingredient among
+// its ingredients, directly or through the recipes it uses
+func recipeUses(foodID, ingredient int) bool {
+	visited := make(map[int]bool)
+	var uses func(foodID int) bool
+	uses = func(foodID int) bool {
+		if visited[foodID] {
+			return false
+		}
+		visited[foodID] = true
+		for _, r := range recipes {
+			if r.FoodID != foodID {
+				continue
+			}
+			for _, ing := range r.Ingredients {
+				if ing.FoodID == ingredient || uses(ing.FoodID) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return uses(foodID)
+}
+
+// rawIngredients splits grams of a food into grams of the foods it is made
+// from. Recipe foods are expanded through their ingredients, scaled by the
+// recipe's yield, until only foods that are bought rather than made remain.
+func rawIngredients(foodID int, grams float64) map[int]float64 {
+	raw := make(map[int]float64)
+	expanding := make(map[int]bool)
+	var expand func(foodID int, grams float64)
+	expand = func(foodID int, grams float64) {
+		var recipe *Recipe
+		for i := range recipes {
+			if recipes[i].FoodID == foodID {
+				recipe = &recipes[i]
+				break
+			}
+		}
+		// A recipe that weighs nothing or uses itself counts as bought
+		if recipe == nil || expanding[foodID] || recipe.yieldGrams() <= 0 {
+			raw[foodID] += grams
+			return
+		}
+
+		expanding[foodID] = true
+		ratio := grams / float64(recipe.yieldGrams())
+		for _, ing := range recipe.Ingredients {
+			expand(ing.FoodID, float64(ing.Grams)*ratio)
+		}
+		expanding[foodID] = false
+	}
+	expand(foodID, grams)
+	return raw
+}
+
+// applyRecipe writes the recipe's totals into its food and reports whether
+// anything changed. A recipe that weighs nothing, for example because its
+// ingredients were deleted, is left as it was rather than given a 0g yield.
+func applyRecipe(r Recipe, food *Food) bool {
+	if r.yieldGrams() <= 0 {
+		return false
+	}
+	before := *food
+
+	price, calories, nutrients, _ := recipeTotals(r.Ingredients)
+	food.Name = r.Name
+	food.RecipeID = r.ID
+	food.Calories = calories
+	food.Nutrients = nutrients
+	food.Quantity = r.yieldGrams()
+	updateFoodStats(food)
+	setServingUnit(food, r.Servings)
+
+	// Ingredient price changes become a new point in the recipe's history
+	if price != food.Price || len(food.PriceHistory) == 0 {
+		recordPrice(food, time.Now().Format("2006-01-02"), price)
+	}
+
+	return food.Name != before.Name ||
+		food.Price != before.Price ||
+		food.Calories != before.Calories ||
+		food.Nutrients != before.Nutrients ||
+		food.Quantity != before.Quantity
+}
+
+// refreshRecipes recomputes every recipe food from its ingredients. Recipes
+// can use other recipes, so it repeats until nothing changes.
+func refreshRecipes() bool {
+	changed := false
+	for pass := 0; pass <= len(recipes); pass++ {
+		passChanged := false
+		for _, r := range recipes {
+			food := getFoodByID(r.FoodID)
+			if food == nil {
+				continue
+			}
+			if applyRecipe(r, food) {
+				passChanged = true
+			}
+		}
+		if !passChanged {
+			break
+		}
+		changed = true
+	}
+	return changed
+}
+
+func showRecipeWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Recipes")
+
+	detailsLabel := widget.NewLabel("Select a recipe to see its details")
+
+	recipeList := widget.NewList(
+		func() int { return len(recipes) },
+		func() fyne.CanvasObject {
+			return container.NewHBox(
+				widget.NewLabel(""), // Recipe name
+				widget.NewLabel(""), // Calories and cost
+			)
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			recipe := recipes[id]
+			box := item.(*fyne.Container)
+			box.Objects[0].(*widget.Label).SetText(recipe.Name)
+
+			if food := getFoodByID(recipe.FoodID); food != nil {
+				box.Objects[1].(*widget.Label).SetText(fmt.Sprintf("%.0f cal, $%.2f", food.Calories, food.Price))
+			}
+		},
+	)
+
+	recipeList.OnSelected = func(id widget.ListItemID) {
+		detailsLabel.SetText(recipeDetails(recipes[id]))
+	}
+
+	newRecipeBtn := widget.NewButton("New Recipe", func() {
+		addRecipe(myApp, func() {
+			recipeList.Refresh()
+		})
+	})
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	listScroll := container.NewScroll(recipeList)
+	listScroll.SetMinSize(fyne.NewSize(380, 200))
+
+	content := container.NewVBox(
+		widget.NewLabel("Recipes"),
+		listScroll,
+		detailsLabel,
+		newRecipeBtn,
+		backBtn,
+	)
+
+	window.SetContent(content)
+	window.Resize(fyne.NewSize(400, 500))
+	window.Show()
+	return window
+}
+
+func recipeDetails(recipe Recipe) string {
+	var details strings.Builder
+
+	details.WriteString(fmt.Sprintf("%s\n", recipe.Name))
+	for _, ing := range recipe.Ingredients {
+		name := "(missing food)"
+		if food := getFoodByID(ing.FoodID); food != nil {
+			name = food.Name
+		}
+		details.WriteString(fmt.Sprintf("  %s: %dg\n", name, ing.Grams))
+	}
+
+	food := getFoodByID(recipe.FoodID)
+	if food == nil {
+		return details.String()
+	}
+
+	details.WriteString(fmt.Sprintf("\nYield: %dg", food.Quantity))
+	if recipe.Servings > 0 {
+		details.WriteString(fmt.Sprintf(" (%d servings of %dg)", recipe.Servings, food.Quantity/recipe.Servings))
+	}
+	details.WriteString(fmt.Sprintf("\nTotal: %.0f cal, $%.2f\n", food.Calories, food.Price))
+	details.WriteString(fmt.Sprintf("Calories per Dollar: %.0f\n", food.CalPerDollar))
+	details.WriteString(fmt.Sprintf("Calories per 100g: %.0f\n", food.CalPer100g))
+	details.WriteString(food.Nutrients.Summary())
+	if recipe.Servings > 0 {
+		details.WriteString(fmt.Sprintf("\nPer serving: %.0f cal, $%.2f",
+			food.Calories/float64(recipe.Servings), food.Price/float64(recipe.Servings)))
+	}
+	return details.String()
+}
+
+func addRecipe(myApp fyne.App, onSaved func()) fyne.Window {
+	window := myApp.NewWindow("New Recipe")
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Recipe Name")
+
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Search for ingredient...")
+
+	gramsEntry := widget.NewEntry()
+	gramsEntry.SetPlaceHolder("Ingredient amount in grams")
+
+	servingsEntry := widget.NewEntry()
+	servingsEntry.SetPlaceHolder("Servings (optional)")
+
+	cookedEntry := widget.NewEntry()
+	cookedEntry.SetPlaceHolder("Total cooked weight in grams (optional)")
+
+	totalsLabel := widget.NewLabel("")
+	statusLabel := widget.NewLabel("")
+
+	var ingredients []RecipeIngredient
+	var matchedFoods []Food
+	var selectedFood *Food
+
+	updateTotals := func() {
+		price, calories, _, grams := recipeTotals(ingredients)
+		totalsLabel.SetText(fmt.Sprintf("Total: %dg raw, %.0f cal, $%.2f", grams, calories, price))
+	}
+
+	resultsList := widget.NewList(
+		func() int { return len(matchedFoods) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			food := matchedFoods[id]
+			item.(*widget.Label).SetText(fmt.Sprintf("%s ($%.2f/%dg, %.0f cal)", food.Name, food.Price, food.Quantity, food.Calories))
+		},
+	)
+	resultsList.OnSelected = func(id widget.ListItemID) {
+		food := matchedFoods[id]
+		selectedFood = &food
+		statusLabel.SetText("Selected: " + food.Name)
+	}
+
+	searchEntry.OnChanged = func(searchText string) {
+		matchedFoods = nil
+		searchText = strings.ToLower(searchText)
+
+		if searchText != "" {
+			for _, food := range foods {
+				if strings.Contains(strings.ToLower(food.Name), searchText) {
+					matchedFoods = append(matchedFoods, food)
+				}
+			}
+		}
+		resultsList.UnselectAll()
+		resultsList.Refresh()
+	}
+
+	var ingredientList *widget.List
+	ingredientList = widget.NewList(
+		func() int { return len(ingredients) },
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, nil, widget.NewButton("Remove", nil), widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			ing := ingredients[id]
+			box := item.(*fyne.Container)
+
+			name := "(missing food)"
+			if food := getFoodByID(ing.FoodID); food != nil {
+				name = food.Name
+			}
+			box.Objects[0].(*widget.Label).SetText(fmt.Sprintf("%s: %dg", name, ing.Grams))
+			box.Objects[1].(*widget.Button).OnTapped = func() {
+				ingredients = append(ingredients[:id], ingredients[id+1:]...)
+				ingredientList.Refresh()
+				updateTotals()
+			}
+		},
+	)
+
+	addIngredientBtn := widget.NewButton("Add Ingredient", func() {
+		if selectedFood == nil {
+			statusLabel.SetText("Please select an ingredient")
+			return
+		}
+		grams, err := strconv.Atoi(gramsEntry.Text)
+		if err != nil || grams <= 0 {
+			statusLabel.SetText("Please enter a valid amount in grams")
+			return
+		}
+
+		ingredients = append(ingredients, RecipeIngredient{FoodID: selectedFood.ID, Grams: grams})
+		ingredientList.Refresh()
+		updateTotals()
+
+		statusLabel.SetText(fmt.Sprintf("Added %s: %dg", selectedFood.Name, grams))
+		searchEntry.SetText("")
+		gramsEntry.SetText("")
+		selectedFood = nil
+	})
+
+	saveBtn := widget.NewButton("Save Recipe", func() {
+		recipe := Recipe{Name: strings.TrimSpace(nameEntry.Text)}
+		if recipe.Name == "" {
+			statusLabel.SetText("Please enter a recipe name")
+			return
+		}
+		if len(ingredients) == 0 {
+			statusLabel.SetText("Please add at least one ingredient")
+			return
+		}
+
+		if servingsEntry.Text != "" {
+			servings, err := strconv.Atoi(servingsEntry.Text)
+			if err != nil || servings <= 0 {
+				statusLabel.SetText("Invalid servings. Please enter a whole number")
+				return
+			}
+			recipe.Servings = servings
+		}
+
+		if cookedEntry.Text != "" {
+			cooked, err := strconv.Atoi(cookedEntry.Text)
+			if err != nil || cooked <= 0 {
+				statusLabel.SetText("Invalid cooked weight. Please enter grams")
+				return
+			}
+			recipe.CookedWeight = cooked
+		}
+
+		recipe.Ingredients = ingredients
+		if recipe.yieldGrams() <= 0 {
+			statusLabel.SetText("The ingredients add up to 0g. Please check their amounts")
+			return
+		}
+
+		// Set ID based on existing recipes
+		maxID := 0
+		for _, r := range recipes {
+			if r.ID > maxID {
+				maxID = r.ID
+			}
+		}
+		recipe.ID = maxID + 1
+
+		// The recipe is logged through its own food entry
+		food := Food{ID: nextFoodID()}
+		recipe.FoodID = food.ID
+		applyRecipe(recipe, &food)
+
+		foods = append(foods, food)
+		recipes = append(recipes, recipe)
+		if err := saveToFile(); err != nil {
+			statusLabel.SetText("Error saving food: " + err.Error())
+			return
+		}
+		if err := saveRecipesToFile(); err != nil {
+			statusLabel.SetText("Error saving recipe: " + err.Error())
+			return
+		}
+
+		if onSaved != nil {
+			onSaved()
+		}
+		statusLabel.SetText(recipeDetails(recipe))
+
+		// Reset for the next recipe
+		ingredients = nil
+		nameEntry.SetText("")
+		servingsEntry.SetText("")
+		cookedEntry.SetText("")
+		ingredientList.Refresh()
+		updateTotals()
+	})
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	resultsScroll := container.NewScroll(resultsList)
+	resultsScroll.SetMinSize(fyne.NewSize(380, 120))
+
+	ingredientScroll := container.NewScroll(ingredientList)
+	ingredientScroll.SetMinSize(fyne.NewSize(380, 120))
+
+	content := container.NewVBox(
+		widget.NewLabel("New Recipe"),
+		nameEntry,
+		searchEntry,
+		resultsScroll,
+		gramsEntry,
+		addIngredientBtn,
+		widget.NewLabel("Ingredients"),
+		ingredientScroll,
+		servingsEntry,
+		cookedEntry,
+		totalsLabel,
+		saveBtn,
+		statusLabel,
+		backBtn,
+	)
+
+	updateTotals()
+	window.SetContent(content)
+	window.Resize(fyne.NewSize(400, 700))
+	window.Show()
+	return window
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// recentFoodLimit caps how many foods an empty search lists
+const recentFoodLimit = 20
+
+// anyGroup is the group select option that leaves foods unfiltered
+const anyGroup = "Any category or tag"
+
+type foodUsage struct {
+	count      int
+	lastLogged string // YYYY-MM-DD
+}
+
+// foodUsageStats counts how often and how recently each food was logged
+func foodUsageStats() map[int]foodUsage {
+	usage := make(map[int]foodUsage)
+	for _, entry := range dailyDiary.Entries {
+		u := usage[entry.FoodID]
+		u.count++
+		if entry.Date > u.lastLogged {
+			u.lastLogged = entry.Date
+		}
+		usage[entry.FoodID] = u
+	}
+	return usage
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// editDistance counts the single character insertions, deletions,
+// substitutions and swaps of neighbouring characters between a and b
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	d := make([][]int, len(ar)+1)
+	for i := range d {
+		d[i] = make([]int, len(br)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(minInt(d[i-1][j]+1, d[i][j-1]+1), d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ar)][len(br)]
+}
+
+// wordScore rates how well one query token matches one word of a food name.
+// A few typos are tolerated, scaled to the token length, and a token can
+// match the start of a longer word that is still being typed.
+func wordScore(word, token string) float64 {
+	switch {
+	case word == token:
+		return 1
+	case strings.HasPrefix(word, token):
+		return 0.9
+	case strings.Contains(word, token):
+		return 0.8
+	}
+
+	tokenLen := utf8.RuneCountInString(token)
+	allowed := tokenLen / 4
+	if allowed == 0 && tokenLen >= 3 {
+		allowed = 1
+	}
+	if allowed == 0 {
+		return 0
+	}
+
+	distance := editDistance(word, token)
+	if wordRunes := []rune(word); len(wordRunes) > tokenLen {
+		distance = minInt(distance, editDistance(string(wordRunes[:tokenLen]), token))
+	}
+	if distance > allowed {
+		return 0
+	}
+	return 0.6 * (1 - float64(distance)/float64(tokenLen+1))
+}
+
+// matchScore rates a food name against a search query from 0 (no match) to
+// 1 (exact match). Every word of the query has to match some word of the
+// name.
+func matchScore(name, query string) float64 {
+	name = strings.ToLower(strings.TrimSpace(name))
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	switch {
+	case query == "":
+		return 0
+	case name == query:
+		return 1
+	case strings.HasPrefix(name, query):
+		return 0.95
+	case strings.Contains(name, query):
+		return 0.85
+	}
+
+	words := strings.Fields(name)
+	tokens := strings.Fields(query)
+	var total float64
+	for _, token := range tokens {
+		best := 0.0
+		for _, word := range words {
+			best = math.Max(best, wordScore(word, token))
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return 0.8 * total / float64(len(tokens))
+}
+
+// usageScore favours foods that are logged often and recently
+func usageScore(u foodUsage, today time.Time) float64 {
+	if u.count == 0 {
+		return 0
+	}
+	score := math.Log1p(float64(u.count)) * 0.05
+
+	if last, err := time.Parse("2006-01-02", u.lastLogged); err == nil {
+		daysAgo := math.Max(0, today.Sub(last).Hours()/24)
+		score += 0.15 * math.Exp(-daysAgo/14)
+	}
+	return score
+}
+
+// rankFoods returns the foods matching query, best first, with favourites
+// pinned to the top. An empty query lists favourites and recently logged
+// foods instead. Words like "#dairy" only keep foods in that category or
+// with that tag.
+func rankFoods(query string) []Food {
+	return rankFoodsInGroups(splitTagQuery(query))
+}
+
+// rankFoodsInGroups ranks like rankFoods, keeping only foods in every one
+// of groups. A group on its own lists all of its foods.
+func rankFoodsInGroups(query string, groups []string) []Food {
+	usage := foodUsageStats()
+	today := time.Now()
+
+	type rankedFood struct {
+		food  Food
+		score float64
+	}
+	var ranked []rankedFood
+
+	for _, food := range foods {
+		if !inAllGroups(food, groups) {
+			continue
+		}
+		u := usage[food.ID]
+		var score float64
+		if strings.TrimSpace(query) == "" {
+			// Listing a whole group shows all of it, not just recent foods
+			if len(groups) == 0 && !food.Favourite && u.count == 0 {
+				continue
+			}
+		} else {
+			score = matchScore(food.Name, query)
+			if score == 0 {
+				continue
+			}
+		}
+		score += usageScore(u, today)
+		ranked = append(ranked, rankedFood{food: food, score: score})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].food.Favourite != ranked[j].food.Favourite {
+			return ranked[i].food.Favourite
+		}
+		if strings.TrimSpace(query) == "" {
+			return usage[ranked[i].food.ID].lastLogged > usage[ranked[j].food.ID].lastLogged
+		}
+		return ranked[i].score > ranked[j].score
+	})
+
+	if strings.TrimSpace(query) == "" && len(groups) == 0 && len(ranked) > recentFoodLimit {
+		ranked = ranked[:recentFoodLimit]
+	}
+
+	results := make([]Food, len(ranked))
+	for i, r := range ranked {
+		results[i] = r.food
+	}
+	return results
+}
+
+// toggleFavourite stars or unstars a food and saves the change
+func toggleFavourite(id int) error {
+	food := getFoodByID(id)
+	if food == nil {
+		return fmt.Errorf("food %d not found", id)
+	}
+	food.Favourite = !food.Favourite
+	return saveToFile()
+}
+
+func favouriteLabel(food Food) string {
+	if food.Favourite {
+		return "★"
+	}
+	return "☆"
+}
+
+// newFoodResultsList shows ranked foods with a star button to pin
+// favourites. results is read on every refresh; onStarred lets the caller
+// re-run its search.
+func newFoodResultsList(results *[]Food, onStarred func()) *widget.List {
+	return widget.NewList(
+		func() int { return len(*results) },
+		func() fyne.CanvasObject {
+			return container.NewHBox(
+				widget.NewButton("☆", nil), // Favourite toggle
+				widget.NewLabel(""),        // Food name
+				widget.NewLabel(""),        // Price and quantity
+				widget.NewLabel(""),        // Calories
+			)
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			food := (*results)[id]
+			box := item.(*fyne.Container)
+
+			starBtn := box.Objects[0].(*widget.Button)
+			starBtn.SetText(favouriteLabel(food))
+			starBtn.OnTapped = func() {
+				if err := toggleFavourite(food.ID); err != nil {
+					log.Printf("Warning: Failed to save favourite: %v", err)
+				}
+				onStarred()
+			}
+
+			box.Objects[1].(*widget.Label).SetText(food.Name)
+			box.Objects[2].(*widget.Label).SetText(fmt.Sprintf("$%.2f/%dg", food.Price, food.Quantity))
+			box.Objects[3].(*widget.Label).SetText(fmt.Sprintf("%.0f cal", food.Calories))
+		},
+	)
+}
+
+// newGroupSelect offers every category and tag in use, and anyGroup
+func newGroupSelect() *widget.Select {
+	groupSelect := widget.NewSelect(append([]string{anyGroup}, allGroups()...), nil)
+	groupSelect.SetSelected(anyGroup)
+	return groupSelect
+}
+
+// refreshGroupOptions brings a group select up to date with the categories
+// and tags in use, going back to anyGroup if the selected one has gone
+func refreshGroupOptions(groupSelect *widget.Select) {
+	groupSelect.Options = append([]string{anyGroup}, allGroups()...)
+	found := false
+	for _, option := range groupSelect.Options {
+		if option == groupSelect.Selected {
+			found = true
+		}
+	}
+	if !found {
+		groupSelect.Selected = anyGroup
+	}
+	groupSelect.Refresh()
+}
+
+func showSearchWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Search Foods")
+
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Search for food, or #tag...")
+
+	headingLabel := widget.NewLabel("")
+	detailsLabel := widget.NewLabel("")
+
+	// Limit results to one category or tag
+	groupSelect := newGroupSelect()
+
+	var results []Food
+	var resultsList *widget.List
+
+	runSearch := func() {
+		// Foods may have been added or retagged since the window opened
+		refreshGroupOptions(groupSelect)
+		query, groups := splitTagQuery(searchEntry.Text)
+		if groupSelect.Selected != anyGroup && groupSelect.Selected != "" {
+			groups = append(groups, groupSelect.Selected)
+		}
+		results = rankFoodsInGroups(query, groups)
+		if strings.TrimSpace(query) == "" && len(groups) == 0 {
+			headingLabel.SetText("Favourites and recent foods")
+		} else {
+			headingLabel.SetText(fmt.Sprintf("%d matching foods", len(results)))
+		}
+		resultsList.UnselectAll()
+		resultsList.Refresh()
+	}
+
+	resultsList = newFoodResultsList(&results, runSearch)
+	resultsList.OnSelected = func(id widget.ListItemID) {
+		food := results[id]
+		detailsLabel.SetText(fmt.Sprintf("%s\nPrice: $%.2f\nQuantity: %dg\nCalories: %.0f\nCalories per Dollar: %.0f\nCalories per 100g: %.0f\n%s\n%s",
+			food.Name, food.Price, food.Quantity, food.Calories, food.CalPerDollar, food.CalPer100g, food.Nutrients.Summary(), tagsSummary(food)))
+	}
+
+	searchEntry.OnChanged = func(string) {
+		runSearch()
+	}
+	groupSelect.OnChanged = func(string) {
+		runSearch()
+	}
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	resultsScroll := container.NewScroll(resultsList)
+	resultsScroll.SetMinSize(fyne.NewSize(430, 250))
+
+	content := container.NewVBox(
+		widget.NewLabel("Search Foods"),
+		searchEntry,
+		groupSelect,
+		headingLabel,
+		resultsScroll,
+		detailsLabel,
+		backBtn,
+	)
+
+	runSearch()
+	window.SetContent(content)
+	window.Resize(fyne.NewSize(450, 550))
+	window.Show()
+	return window
+}
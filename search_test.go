@@ -0,0 +1,120 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "milk", 4},
+		{"milk", "milk", 0},
+		{"milk", "silk", 1},     // substitution
+		{"milk", "milks", 1},    // insertion
+		{"bread", "bred", 1},    // deletion
+		{"yogurt", "yougrt", 1}, // swap of neighbours
+		{"banana", "bnaana", 1}, // swap of neighbours
+		{"apple", "orange", 5},
+		{"crème", "creme", 1}, // counts runes, not bytes
+	}
+	for _, tt := range tests {
+		// The distance is the same in both directions
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := editDistance(tt.b, tt.a); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestMatchScore(t *testing.T) {
+	tests := []struct {
+		name, query string
+		want        float64
+	}{
+		{"Milk", "", 0},
+		{"Milk", "milk", 1},
+		{"Whole Milk", "  WHOLE MILK ", 1},
+		{"Milk Chocolate", "milk", 0.95},
+		{"Whole Milk", "milk", 0.85},
+		{"Greek Yogurt", "carrot", 0},
+		{"Greek Yogurt", "yogurt greek", 0.8}, // word order does not matter
+		{"Greek Yogurt", "yogurt cheese", 0},  // every word has to match
+		{"Greek Yogurt", "yog gre", 0.72},     // word prefixes
+		{"Brown Rice", "rice brwn", 0.592},    // one typo in a four letter word
+		{"Oat Milk", "ot", 0},                 // too short to allow a typo
+		{"Chicken Breast", "chikcen", 0.42},   // one swapped pair
+	}
+	for _, tt := range tests {
+		if got := matchScore(tt.name, tt.query); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("matchScore(%q, %q) = %.3f, want %.3f", tt.name, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestRankFoodsOrdering(t *testing.T) {
+	savedFoods, savedDiary := foods, dailyDiary
+	t.Cleanup(func() {
+		foods, dailyDiary = savedFoods, savedDiary
+	})
+
+	today := time.Now().Format("2006-01-02")
+	lastMonth := time.Now().AddDate(0, -1, 0).Format("2006-01-02")
+	foods = []Food{
+		{ID: 1, Name: "Milk"},
+		{ID: 2, Name: "Milk Chocolate"},
+		{ID: 3, Name: "Oat Milk", Category: "Dairy Alternatives"},
+		{ID: 4, Name: "Mlik Powder"},
+		{ID: 5, Name: "Almond Milk", Favourite: true, Category: "Dairy Alternatives"},
+		{ID: 6, Name: "Bread", Tags: []string{"breakfast"}},
+		{ID: 7, Name: "Cheese", Category: "dairy"},
+	}
+	dailyDiary = DailyDiary{Entries: []DiaryEntry{
+		{ID: 1, FoodID: 6, Date: today},
+		{ID: 2, FoodID: 7, Date: lastMonth},
+		{ID: 3, FoodID: 7, Date: lastMonth},
+	}}
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		// Favourites first, then exact, prefix, contains, then typos
+		{"milk", []int{5, 1, 2, 3, 4}},
+		// Nothing typed lists favourites, then the most recently logged
+		{"", []int{5, 6, 7}},
+		// A tag on its own lists the whole group, logged or not
+		{"#breakfast", []int{6}},
+		{"#dairy", []int{7}},
+		// Words are searched for within the group
+		{"oat #dairy", nil},
+		// Typed tags are single words, so "alternatives" is a search word
+		{"#dairy alternatives", nil},
+		{"zzz", nil},
+	}
+	for _, tt := range tests {
+		var got []int
+		for _, food := range rankFoods(tt.query) {
+			got = append(got, food.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("rankFoods(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	// A category with a space is picked from the group select, not typed
+	var got []int
+	for _, food := range rankFoodsInGroups("milk", []string{"dairy alternatives"}) {
+		got = append(got, food.ID)
+	}
+	if want := []int{5, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rankFoodsInGroups(milk, dairy alternatives) = %v, want %v", got, want)
+	}
+}
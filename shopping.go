@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// shoppingItem is one food to buy, in grams wanted and whole packs
+type shoppingItem struct {
+	Food    Food
+	Grams   float64
+	Checked bool
+}
+
+// Packs rounds the grams wanted up to whole packs of the food
+func (item shoppingItem) Packs() int {
+	if item.Food.Quantity <= 0 || item.Grams <= 0 {
+		return 0
+	}
+	return int(math.Ceil(item.Grams/float64(item.Food.Quantity) - 1e-9))
+}
+
+func (item shoppingItem) Cost() float64 {
+	return float64(item.Packs()) * item.Food.Price
+}
+
+// consumedSince totals the grams of each food eaten in the last days days,
+// today included. Recipes are counted as their ingredients, since those are
+// what gets bought.
+func consumedSince(days int, today time.Time) map[int]float64 {
+	since := today.AddDate(0, 0, -days).Format("2006-01-02")
+	until := today.Format("2006-01-02")
+	eaten := make(map[int]float64)
+	for _, entry := range dailyDiary.Entries {
+		if entry.Date > since && entry.Date <= until {
+			for foodID, grams := range rawIngredients(entry.FoodID, float64(entry.Quantity)) {
+				eaten[foodID] += grams
+			}
+		}
+	}
+	return eaten
+}
+
+// buildShoppingList restocks what was eaten over the last days days. With
+// subtractStock, food already in the pantry is taken off the list.
+func buildShoppingList(days int, subtractStock bool) []shoppingItem {
+	eaten := consumedSince(days, time.Now())
+	var stock map[int]float64
+	if subtractStock {
+		stock = pantryStock()
+	}
+
+	var items []shoppingItem
+	for _, food := range foods {
+		grams := eaten[food.ID] - stock[food.ID]
+		if grams <= 0 {
+			continue
+		}
+		items = append(items, shoppingItem{Food: food, Grams: math.Ceil(grams)})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return strings.ToLower(items[i].Food.Name) < strings.ToLower(items[j].Food.Name)
+	})
+	return items
+}
+
+func shoppingListCost(items []shoppingItem) float64 {
+	var total float64
+	for _, item := range items {
+		total += item.Cost()
+	}
+	return total
+}
+
+// shoppingListText writes the list as plain text, or as a Markdown task
+// list that keeps the ticks
+func shoppingListText(items []shoppingItem, markdown bool) string {
+	var text strings.Builder
+	if markdown {
+		text.WriteString("# Shopping List\n\n")
+	} else {
+		text.WriteString("Shopping List\n\n")
+	}
+
+	for _, item := range items {
+		line := fmt.Sprintf("%s: %d x %dg (%.0fg needed) - $%.2f",
+			item.Food.Name, item.Packs(), item.Food.Quantity, item.Grams, item.Cost())
+		switch {
+		case markdown && item.Checked:
+			text.WriteString("- [x] " + line + "\n")
+		case markdown:
+			text.WriteString("- [ ] " + line + "\n")
+		case item.Checked:
+			text.WriteString("[x] " + line + "\n")
+		default:
+			text.WriteString("[ ] " + line + "\n")
+		}
+	}
+
+	total := fmt.Sprintf("Estimated cost: $%.2f", shoppingListCost(items))
+	if markdown {
+		total = "**" + total + "**"
+	}
+	text.WriteString("\n" + total + "\n")
+	return text.String()
+}
+
+func showShoppingListWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Shopping List")
+
+	daysEntry := widget.NewEntry()
+	daysEntry.SetText("7")
+	subtractCheck := widget.NewCheck("Take off what is already in the pantry", nil)
+	subtractCheck.SetChecked(len(purchases) > 0)
+
+	itemsBox := container.NewVBox()
+	totalLabel := widget.NewLabel("")
+	statusLabel := widget.NewLabel("")
+
+	var items []shoppingItem
+
+	updateTotal := func() {
+		remaining := 0.0
+		for _, item := range items {
+			if !item.Checked {
+				remaining += item.Cost()
+			}
+		}
+		totalLabel.SetText(fmt.Sprintf("Estimated trip cost: $%.2f ($%.2f still to get)", shoppingListCost(items), remaining))
+	}
+
+	// Each row has a tick, an adjustable amount and its packs and cost
+	showItems := func() {
+		itemsBox.Objects = nil
+		for i := range items {
+			i := i
+			packsLabel := widget.NewLabel("")
+			showPacks := func() {
+				packsLabel.SetText(fmt.Sprintf("%d x %dg, $%.2f", items[i].Packs(), items[i].Food.Quantity, items[i].Cost()))
+			}
+			showPacks()
+
+			check := widget.NewCheck(items[i].Food.Name, func(checked bool) {
+				items[i].Checked = checked
+				updateTotal()
+			})
+			check.SetChecked(items[i].Checked)
+
+			gramsEntry := widget.NewEntry()
+			gramsEntry.SetText(strconv.FormatFloat(items[i].Grams, 'f', -1, 64))
+			gramsEntry.OnChanged = func(text string) {
+				grams, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+				if err != nil || grams < 0 {
+					return
+				}
+				items[i].Grams = grams
+				showPacks()
+				updateTotal()
+			}
+
+			itemsBox.Add(container.NewGridWithColumns(3, check, gramsEntry, packsLabel))
+		}
+		itemsBox.Refresh()
+		updateTotal()
+	}
+
+	generateBtn := widget.NewButton("Build List", func() {
+		days, err := strconv.Atoi(strings.TrimSpace(daysEntry.Text))
+		if err != nil || days <= 0 {
+			statusLabel.SetText("Please enter a number of days")
+			return
+		}
+		items = buildShoppingList(days, subtractCheck.Checked)
+		if len(items) == 0 {
+			statusLabel.SetText(fmt.Sprintf("Nothing to restock from the last %d days", days))
+		} else {
+			statusLabel.SetText(fmt.Sprintf("%d foods eaten in the last %d days", len(items), days))
+		}
+		showItems()
+	})
+
+	export := func(markdown bool) {
+		if len(items) == 0 {
+			statusLabel.SetText("Build a list first")
+			return
+		}
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				return
+			}
+			defer writer.Close()
+			if _, err := writer.Write([]byte(shoppingListText(items, markdown))); err != nil {
+				statusLabel.SetText("Error exporting list: " + err.Error())
+				return
+			}
+			statusLabel.SetText("Exported to " + writer.URI().Path())
+		}, window)
+		if markdown {
+			saveDialog.SetFileName("shopping-list.md")
+		} else {
+			saveDialog.SetFileName("shopping-list.txt")
+		}
+		saveDialog.Show()
+	}
+
+	exportTextBtn := widget.NewButton("Export Text", func() {
+		export(false)
+	})
+	exportMarkdownBtn := widget.NewButton("Export Markdown", func() {
+		export(true)
+	})
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	optionsForm := widget.NewForm(
+		widget.NewFormItem("Restock the last (days)", daysEntry),
+		widget.NewFormItem("", subtractCheck),
+	)
+
+	itemsScroll := container.NewVScroll(itemsBox)
+	itemsScroll.SetMinSize(fyne.NewSize(480, 300))
+
+	content := container.NewVBox(
+		widget.NewLabel("Shopping List"),
+		optionsForm,
+		generateBtn,
+		statusLabel,
+		widget.NewLabel("Food, grams needed, packs to buy"),
+		itemsScroll,
+		totalLabel,
+		container.NewGridWithColumns(2, exportTextBtn, exportMarkdownBtn),
+		backBtn,
+	)
+
+	window.SetContent(content)
+	window.Resize(fyne.NewSize(520, 650))
+	window.Show()
+	return window
+}
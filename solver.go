@@ -0,0 +1,220 @@
+package main
+
+import (
+	"errors"
+	"math"
+)
+
+// lpRelation is how a constraint's left side compares to its right side
+type lpRelation int
+
+const (
+	atMost lpRelation = iota
+	atLeast
+	exactly
+)
+
+// lpConstraint is coeffs·x (relation) rhs
+type lpConstraint struct {
+	coeffs   []float64
+	relation lpRelation
+	rhs      float64
+}
+
+var (
+	errInfeasible = errors.New("no solution meets every constraint")
+	errUnbounded  = errors.New("the objective has no lower bound")
+)
+
+const lpEpsilon = 1e-9
+
+// simplexTableau keeps the constraints as rows [A | b] in the basis given
+// by basis[row]
+type simplexTableau struct {
+	rows  [][]float64
+	basis []int
+	cols  int
+}
+
+func (t *simplexTableau) rhs(row int) float64 {
+	return t.rows[row][t.cols]
+}
+
+// pivot makes col basic in row
+func (t *simplexTableau) pivot(row, col int) {
+	pivotRow := t.rows[row]
+	scale := pivotRow[col]
+	for j := range pivotRow {
+		pivotRow[j] /= scale
+	}
+	for i, r := range t.rows {
+		if i == row || r[col] == 0 {
+			continue
+		}
+		factor := r[col]
+		for j := range r {
+			r[j] -= factor * pivotRow[j]
+		}
+	}
+	t.basis[row] = col
+}
+
+// optimise pivots until no allowed column lowers cost·x. Bland's rule,
+// taking the lowest eligible column and row, keeps it from cycling.
+func (t *simplexTableau) optimise(cost []float64, allowed func(col int) bool) error {
+	maxIterations := 50 * (len(t.rows) + t.cols)
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		entering := -1
+		for j := 0; j < t.cols; j++ {
+			if !allowed(j) {
+				continue
+			}
+			reduced := cost[j]
+			for i, r := range t.rows {
+				reduced -= cost[t.basis[i]] * r[j]
+			}
+			if reduced < -lpEpsilon {
+				entering = j
+				break
+			}
+		}
+		if entering < 0 {
+			return nil
+		}
+
+		leaving := -1
+		bestRatio := math.Inf(1)
+		for i, r := range t.rows {
+			if r[entering] <= lpEpsilon {
+				continue
+			}
+			ratio := t.rhs(i) / r[entering]
+			if ratio < bestRatio-lpEpsilon ||
+				(math.Abs(ratio-bestRatio) <= lpEpsilon && t.basis[i] < t.basis[leaving]) {
+				bestRatio = ratio
+				leaving = i
+			}
+		}
+		if leaving < 0 {
+			return errUnbounded
+		}
+		t.pivot(leaving, entering)
+	}
+	return errors.New("the solver did not converge")
+}
+
+// minimize finds x >= 0 that minimises objective·x subject to constraints,
+// using the two-phase simplex method
+func minimize(objective []float64, constraints []lpConstraint) ([]float64, error) {
+	n := len(objective)
+
+	// Count the slack, surplus and artificial columns needed
+	slacks, artificials := 0, 0
+	for _, c := range constraints {
+		relation := c.relation
+		if c.rhs < 0 && relation != exactly {
+			relation = 1 - relation
+		}
+		switch relation {
+		case atMost:
+			slacks++
+		case atLeast:
+			slacks++
+			artificials++
+		case exactly:
+			artificials++
+		}
+	}
+
+	t := &simplexTableau{cols: n + slacks + artificials}
+	firstArtificial := n + slacks
+	nextSlack, nextArtificial := n, firstArtificial
+
+	for _, c := range constraints {
+		row := make([]float64, t.cols+1)
+		copy(row, c.coeffs)
+		row[t.cols] = c.rhs
+		relation := c.relation
+
+		// Keep every right hand side non-negative
+		if c.rhs < 0 {
+			for j := 0; j < n; j++ {
+				row[j] = -row[j]
+			}
+			row[t.cols] = -c.rhs
+			if relation != exactly {
+				relation = 1 - relation
+			}
+		}
+
+		switch relation {
+		case atMost:
+			row[nextSlack] = 1
+			t.basis = append(t.basis, nextSlack)
+			nextSlack++
+		case atLeast:
+			row[nextSlack] = -1
+			nextSlack++
+			row[nextArtificial] = 1
+			t.basis = append(t.basis, nextArtificial)
+			nextArtificial++
+		case exactly:
+			row[nextArtificial] = 1
+			t.basis = append(t.basis, nextArtificial)
+			nextArtificial++
+		}
+		t.rows = append(t.rows, row)
+	}
+
+	// Phase one: drive the artificial columns to zero to find a feasible start
+	if artificials > 0 {
+		phaseOne := make([]float64, t.cols)
+		for j := firstArtificial; j < t.cols; j++ {
+			phaseOne[j] = 1
+		}
+		all := func(int) bool { return true }
+		if err := t.optimise(phaseOne, all); err != nil {
+			return nil, err
+		}
+
+		var infeasibility float64
+		for i, col := range t.basis {
+			if col >= firstArtificial {
+				infeasibility += t.rhs(i)
+			}
+		}
+		if infeasibility > 1e-7 {
+			return nil, errInfeasible
+		}
+
+		// Swap any artificial left in the basis at zero for a real column.
+		// A row with no real column to swap in is redundant and stays at zero.
+		for i, col := range t.basis {
+			if col < firstArtificial {
+				continue
+			}
+			for j := 0; j < firstArtificial; j++ {
+				if math.Abs(t.rows[i][j]) > lpEpsilon {
+					t.pivot(i, j)
+					break
+				}
+			}
+		}
+	}
+
+	// Phase two: minimise the real objective without the artificials
+	phaseTwo := make([]float64, t.cols)
+	copy(phaseTwo, objective)
+	notArtificial := func(col int) bool { return col < firstArtificial }
+	if err := t.optimise(phaseTwo, notArtificial); err != nil {
+		return nil, err
+	}
+
+	x := make([]float64, n)
+	for i, col := range t.basis {
+		if col < n {
+			x[col] = t.rhs(i)
+		}
+	}
+	return x, nil
+}
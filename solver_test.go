@@ -0,0 +1,118 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMinimize(t *testing.T) {
+	tests := []struct {
+		name        string
+		objective   []float64
+		constraints []lpConstraint
+		want        []float64
+		wantErr     error
+	}{
+		{
+			name:      "feasible with upper bounds",
+			objective: []float64{-1, -1},
+			constraints: []lpConstraint{
+				{coeffs: []float64{1, 2}, relation: atMost, rhs: 4},
+				{coeffs: []float64{3, 1}, relation: atMost, rhs: 6},
+			},
+			want: []float64{1.6, 1.2},
+		},
+		{
+			name:      "feasible with lower bounds",
+			objective: []float64{2, 3},
+			constraints: []lpConstraint{
+				{coeffs: []float64{1, 1}, relation: atLeast, rhs: 4},
+				{coeffs: []float64{1, 3}, relation: atLeast, rhs: 6},
+			},
+			want: []float64{3, 1},
+		},
+		{
+			name:      "negative right hand side",
+			objective: []float64{1},
+			constraints: []lpConstraint{
+				{coeffs: []float64{-1}, relation: atMost, rhs: -2},
+			},
+			want: []float64{2},
+		},
+		{
+			name:      "equality constraints",
+			objective: []float64{1, 1},
+			constraints: []lpConstraint{
+				{coeffs: []float64{1, 1}, relation: exactly, rhs: 5},
+				{coeffs: []float64{1, -1}, relation: exactly, rhs: -1},
+			},
+			want: []float64{2, 3},
+		},
+		{
+			name:      "redundant equality",
+			objective: []float64{1, 0},
+			constraints: []lpConstraint{
+				{coeffs: []float64{1, 1}, relation: exactly, rhs: 2},
+				{coeffs: []float64{2, 2}, relation: exactly, rhs: 4},
+			},
+			want: []float64{0, 2},
+		},
+		{
+			// Beale's example cycles without an anti-cycling rule
+			name:      "degenerate pivots",
+			objective: []float64{-0.75, 20, -0.5, 6},
+			constraints: []lpConstraint{
+				{coeffs: []float64{0.25, -8, -1, 9}, relation: atMost, rhs: 0},
+				{coeffs: []float64{0.5, -12, -0.5, 3}, relation: atMost, rhs: 0},
+				{coeffs: []float64{0, 0, 1, 0}, relation: atMost, rhs: 1},
+			},
+			want: []float64{1, 0, 1, 0},
+		},
+		{
+			name:      "infeasible",
+			objective: []float64{1},
+			constraints: []lpConstraint{
+				{coeffs: []float64{1}, relation: atMost, rhs: 1},
+				{coeffs: []float64{1}, relation: atLeast, rhs: 2},
+			},
+			wantErr: errInfeasible,
+		},
+		{
+			name:      "infeasible equality",
+			objective: []float64{0, 0},
+			constraints: []lpConstraint{
+				{coeffs: []float64{1, 1}, relation: exactly, rhs: 1},
+				{coeffs: []float64{1, 1}, relation: exactly, rhs: 2},
+			},
+			wantErr: errInfeasible,
+		},
+		{
+			name:      "unbounded",
+			objective: []float64{-1, 0},
+			constraints: []lpConstraint{
+				{coeffs: []float64{1, -1}, relation: atMost, rhs: 1},
+			},
+			wantErr: errUnbounded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := minimize(tt.objective, tt.constraints)
+			if err != tt.wantErr {
+				t.Fatalf("minimize() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("minimize() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.want[i]) > 1e-6 {
+					t.Fatalf("minimize() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"sort"
+	"strings"
+)
+
+// foodStat is one food's row in the stats table
+type foodStat struct {
+	Food        Food
+	TimesLogged int
+	TotalSpent  float64
+}
+
+// foodStats pairs every food with how often it was logged and what it cost
+// in total, at the prices in force when it was eaten
+func foodStats() []foodStat {
+	usage := foodUsageStats()
+	spent := make(map[int]float64)
+	for _, entry := range dailyDiary.Entries {
+		spent[entry.FoodID] += entry.Cost
+	}
+
+	stats := make([]foodStat, len(foods))
+	for i, food := range foods {
+		stats[i] = foodStat{Food: food, TimesLogged: usage[food.ID].count, TotalSpent: spent[food.ID]}
+	}
+	return stats
+}
+
+// statColumn is one sortable column of the stats table
+type statColumn struct {
+	title  string
+	width  float32
+	text   func(foodStat) string
+	value  func(foodStat) float64 // nil sorts by text
+	format string                 // for bar charts; empty when not charted
+}
+
+var statColumns = []statColumn{
+	{title: "Food", width: 180, text: func(s foodStat) string { return s.Food.Name }},
+	{title: "Price", width: 70,
+		text:  func(s foodStat) string { return fmt.Sprintf("$%.2f", s.Food.Price) },
+		value: func(s foodStat) float64 { return s.Food.Price }, format: "$%.2f"},
+	{title: "Grams", width: 70,
+		text:  func(s foodStat) string { return fmt.Sprintf("%d", s.Food.Quantity) },
+		value: func(s foodStat) float64 { return float64(s.Food.Quantity) }},
+	{title: "Calories", width: 80,
+		text:  func(s foodStat) string { return fmt.Sprintf("%.0f", s.Food.Calories) },
+		value: func(s foodStat) float64 { return s.Food.Calories }},
+	{title: "Cal/$", width: 70,
+		text:  func(s foodStat) string { return fmt.Sprintf("%.0f", s.Food.CalPerDollar) },
+		value: func(s foodStat) float64 { return s.Food.CalPerDollar }, format: "%.0f"},
+	{title: "Cal/100g", width: 80,
+		text:  func(s foodStat) string { return fmt.Sprintf("%.0f", s.Food.CalPer100g) },
+		value: func(s foodStat) float64 { return s.Food.CalPer100g }, format: "%.0f"},
+	{title: "Logged", width: 70,
+		text:  func(s foodStat) string { return fmt.Sprintf("%d", s.TimesLogged) },
+		value: func(s foodStat) float64 { return float64(s.TimesLogged) }, format: "%.0f"},
+	{title: "Spent", width: 80,
+		text:  func(s foodStat) string { return fmt.Sprintf("$%.2f", s.TotalSpent) },
+		value: func(s foodStat) float64 { return s.TotalSpent }, format: "$%.2f"},
+}
+
+// calPerDollarColumn is the column the table and charts start on
+const calPerDollarColumn = 4
+
+// sortFoodStats orders stats by a column, keeping ties in name order
+func sortFoodStats(stats []foodStat, column int, descending bool) {
+	col := statColumns[column]
+	sort.SliceStable(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if col.value == nil || col.value(a) == col.value(b) {
+			nameA, nameB := strings.ToLower(a.Food.Name), strings.ToLower(b.Food.Name)
+			if col.value == nil && descending {
+				return nameA > nameB
+			}
+			return nameA < nameB
+		}
+		if descending {
+			return col.value(a) > col.value(b)
+		}
+		return col.value(a) < col.value(b)
+	})
+}
+
+// statBars charts the top and bottom count foods by a column. With fewer
+// than twice count foods the bottom chart only takes those not already in
+// the top one, and is empty when there are none.
+func statBars(stats []foodStat, column, count int) (top, bottom []chartBar) {
+	sorted := append([]foodStat(nil), stats...)
+	sortFoodStats(sorted, column, true)
+
+	col := statColumns[column]
+	for i := 0; i < minInt(count, len(sorted)); i++ {
+		top = append(top, chartBar{Label: sorted[i].Food.Name, Value: col.value(sorted[i])})
+	}
+	for i := len(sorted) - 1; i >= len(top) && i >= len(sorted)-count; i-- {
+		bottom = append(bottom, chartBar{Label: sorted[i].Food.Name, Value: col.value(sorted[i])})
+	}
+	return top, bottom
+}
+
+func showStatsWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Food Stats")
+
+	const chartBarCount = 5
+
+	filterEntry := widget.NewEntry()
+	filterEntry.SetPlaceHolder("Filter foods...")
+	loggedOnlyCheck := widget.NewCheck("Only foods I have logged", nil)
+	favouritesOnlyCheck := widget.NewCheck("Only favourites", nil)
+
+	// Limit the table and charts to one category or tag, with its totals
+	groupSelect := newGroupSelect()
+	groupLabel := widget.NewLabel("")
+
+	sortColumn, descending := calPerDollarColumn, true // best value first
+	var stats []foodStat
+
+	// Row 0 holds the column headings; tapping one sorts by it
+	table := widget.NewTable(
+		func() (int, int) { return len(stats) + 1, len(statColumns) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.TableCellID, cell fyne.CanvasObject) {
+			label := cell.(*widget.Label)
+			if id.Row == 0 {
+				title := statColumns[id.Col].title
+				if id.Col == sortColumn {
+					if descending {
+						title += " ▼"
+					} else {
+						title += " ▲"
+					}
+				}
+				label.TextStyle = fyne.TextStyle{Bold: true}
+				label.SetText(title)
+				return
+			}
+			label.TextStyle = fyne.TextStyle{}
+			label.SetText(statColumns[id.Col].text(stats[id.Row-1]))
+		},
+	)
+	for i, col := range statColumns {
+		table.SetColumnWidth(i, col.width)
+	}
+
+	// Charted columns, offered by title
+	var chartTitles []string
+	for _, col := range statColumns {
+		if col.format != "" {
+			chartTitles = append(chartTitles, col.title)
+		}
+	}
+	chartSelect := widget.NewSelect(chartTitles, nil)
+	topChart := container.NewMax()
+	bottomChart := container.NewMax()
+	topLabel := widget.NewLabel("")
+	bottomLabel := widget.NewLabel("")
+	countLabel := widget.NewLabel("")
+
+	showCharts := func() {
+		column := -1
+		for i, col := range statColumns {
+			if col.title == chartSelect.Selected {
+				column = i
+			}
+		}
+		if column < 0 {
+			return
+		}
+		top, bottom := statBars(stats, column, chartBarCount)
+		format := statColumns[column].format
+		topLabel.SetText(fmt.Sprintf("Top %d by %s", len(top), chartSelect.Selected))
+		topChart.Objects = []fyne.CanvasObject{newBarChart(top, 560, format)}
+		topChart.Refresh()
+		if len(bottom) == 0 {
+			bottomLabel.Hide()
+			bottomChart.Hide()
+			return
+		}
+		bottomLabel.SetText(fmt.Sprintf("Bottom %d by %s", len(bottom), chartSelect.Selected))
+		bottomLabel.Show()
+		bottomChart.Objects = []fyne.CanvasObject{newBarChart(bottom, 560, format)}
+		bottomChart.Refresh()
+		bottomChart.Show()
+	}
+	chartSelect.OnChanged = func(string) {
+		showCharts()
+	}
+
+	refresh := func() {
+		refreshGroupOptions(groupSelect)
+		group := groupSelect.Selected
+		if group == anyGroup {
+			groupLabel.SetText("")
+		} else {
+			var calories, cost float64
+			entries := entriesInGroup(dailyDiary.Entries, group)
+			for _, entry := range entries {
+				calories += entry.Calories
+				cost += entry.Cost
+			}
+			groupLabel.SetText(fmt.Sprintf("%s: logged %d times, %.0f cal, $%.2f spent", group, len(entries), calories, cost))
+		}
+
+		query := strings.TrimSpace(filterEntry.Text)
+		stats = nil
+		for _, s := range foodStats() {
+			if group != anyGroup && !s.Food.inGroup(group) {
+				continue
+			}
+			if query != "" && matchScore(s.Food.Name, query) == 0 {
+				continue
+			}
+			if loggedOnlyCheck.Checked && s.TimesLogged == 0 {
+				continue
+			}
+			if favouritesOnlyCheck.Checked && !s.Food.Favourite {
+				continue
+			}
+			stats = append(stats, s)
+		}
+		sortFoodStats(stats, sortColumn, descending)
+		countLabel.SetText(fmt.Sprintf("%d of %d foods", len(stats), len(foods)))
+		table.Refresh()
+		showCharts()
+	}
+
+	table.OnSelected = func(id widget.TableCellID) {
+		table.UnselectAll()
+		if id.Row != 0 {
+			return
+		}
+		if id.Col == sortColumn {
+			descending = !descending
+		} else {
+			// Numbers read best largest first, names from A
+			sortColumn = id.Col
+			descending = statColumns[id.Col].value != nil
+		}
+		refresh()
+	}
+
+	filterEntry.OnChanged = func(string) {
+		refresh()
+	}
+	loggedOnlyCheck.OnChanged = func(bool) {
+		refresh()
+	}
+	favouritesOnlyCheck.OnChanged = func(bool) {
+		refresh()
+	}
+	groupSelect.OnChanged = func(string) {
+		refresh()
+	}
+
+	// Times logged and spending follow the diary
+	removeListener := addDiaryListener(refresh)
+	window.SetOnClosed(removeListener)
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	charts := container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewLabel("Chart"), nil, chartSelect),
+		topLabel,
+		topChart,
+		bottomLabel,
+		bottomChart,
+	)
+
+	content := container.NewBorder(
+		container.NewVBox(
+			widget.NewLabelWithStyle("Food Stats", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+			filterEntry,
+			container.NewHBox(loggedOnlyCheck, favouritesOnlyCheck),
+			groupSelect,
+			groupLabel,
+			widget.NewLabel("Tap a heading to sort"),
+			countLabel,
+		),
+		container.NewVBox(charts, backBtn),
+		nil, nil,
+		table,
+	)
+
+	chartSelect.SetSelected(statColumns[calPerDollarColumn].title)
+	refresh()
+	window.SetContent(content)
+	window.Resize(fyne.NewSize(720, 850))
+	window.Show()
+	return window
+}
@@ -2,10 +2,14 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -19,25 +23,44 @@ const (
 	YesNo         TrackingType = "yesno"
 	Counter       TrackingType = "counter"
 	Notes         TrackingType = "notes"
+	Measurement   TrackingType = "measurement"
+	Duration      TrackingType = "duration"
 )
 
+// MeasurementPart is one value of a measurement. Most measurements have a
+// single unnamed part; blood pressure has systolic and diastolic.
+type MeasurementPart struct {
+	Name      string  `json:"name,omitempty"`
+	NormalMin float64 `json:"normal_min,omitempty"`
+	NormalMax float64 `json:"normal_max,omitempty"`
+}
+
 type Symptom struct {
 	ID           int          `json:"id"`
 	Name         string       `json:"name"`
 	TrackingType TrackingType `json:"tracking_type"`
 	ScaleMin     int          `json:"scale_min,omitempty"`
 	ScaleMax     int          `json:"scale_max,omitempty"`
+	Archived     bool         `json:"archived,omitempty"` // no longer logged, history kept
+
+	// Measurements only
+	Unit  string            `json:"unit,omitempty"`
+	Parts []MeasurementPart `json:"parts,omitempty"`
 }
 
 type SymptomEntry struct {
 	ID            int    `json:"id"`
-	Date          string `json:"date"`
+	Date          string `json:"date"`           // YYYY-MM-DD
+	Time          string `json:"time,omitempty"` // HH:MM local; empty on older day-only entries
 	SymptomID     int    `json:"symptom_id"`
 	SymptomName   string `json:"symptom_name"`
 	SeverityValue int    `json:"severity_value,omitempty"`
 	YesNoValue    bool   `json:"yes_no_value,omitempty"`
 	CountValue    int    `json:"count_value,omitempty"`
 	Notes         string `json:"notes,omitempty"`
+
+	Measurements    []float64 `json:"measurements,omitempty"` // one per part of the symptom
+	DurationMinutes int       `json:"duration_minutes,omitempty"`
 }
 
 type SymptomDiary struct {
@@ -47,6 +70,130 @@ type SymptomDiary struct {
 
 var symptomDiary SymptomDiary
 
+// trackingTypes lists the ways a symptom can be tracked, in menu order
+var trackingTypes = []TrackingType{SeverityScale, YesNo, Counter, Notes, Measurement, Duration}
+
+func (t TrackingType) label() string {
+	switch t {
+	case SeverityScale:
+		return "Severity scale"
+	case YesNo:
+		return "Yes/No"
+	case Counter:
+		return "Counter"
+	case Notes:
+		return "Notes only"
+	case Measurement:
+		return "Measurement"
+	case Duration:
+		return "Duration"
+	}
+	return string(t)
+}
+
+// hasRange reports whether a normal range was given for the part
+func (p MeasurementPart) hasRange() bool {
+	return p.NormalMax > p.NormalMin
+}
+
+// rangeText is the normal range as "low-high", or "" when there is none
+func (p MeasurementPart) rangeText() string {
+	if !p.hasRange() {
+		return ""
+	}
+	return formatMeasurement(p.NormalMin) + "-" + formatMeasurement(p.NormalMax)
+}
+
+// rangeNote says whether a value is below or above the part's normal range
+func (p MeasurementPart) rangeNote(value float64) string {
+	switch {
+	case !p.hasRange():
+		return ""
+	case value < p.NormalMin:
+		return "low"
+	case value > p.NormalMax:
+		return "high"
+	}
+	return ""
+}
+
+// measurementParts are the values a measurement takes, one when none are
+// named
+func (s Symptom) measurementParts() []MeasurementPart {
+	if len(s.Parts) == 0 {
+		return []MeasurementPart{{}}
+	}
+	return s.Parts
+}
+
+// normalRangeText describes a measurement's normal ranges, e.g.
+// "normal 90-120/60-80 mmHg", or "" when none were given
+func (s Symptom) normalRangeText() string {
+	var ranges []string
+	hasRange := false
+	for _, part := range s.measurementParts() {
+		if part.hasRange() {
+			hasRange = true
+			ranges = append(ranges, part.rangeText())
+		} else {
+			ranges = append(ranges, "?")
+		}
+	}
+	if !hasRange {
+		return ""
+	}
+	return "normal " + strings.Join(ranges, "/") + " " + s.Unit
+}
+
+func formatMeasurement(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// parseNormalRange reads a range such as "36.1-37.2". Empty means no range.
+func parseNormalRange(text string) (float64, float64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, 0, nil
+	}
+	bounds := strings.SplitN(text, "-", 2)
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("please enter the normal range as low-high, e.g. 36.1-37.2")
+	}
+	low, errLow := strconv.ParseFloat(strings.TrimSpace(bounds[0]), 64)
+	high, errHigh := strconv.ParseFloat(strings.TrimSpace(bounds[1]), 64)
+	if errLow != nil || errHigh != nil {
+		return 0, 0, fmt.Errorf("please enter the normal range as low-high, e.g. 36.1-37.2")
+	}
+	if high <= low {
+		return 0, 0, fmt.Errorf("the top of the normal range must be above the bottom")
+	}
+	return low, high, nil
+}
+
+// parseDurationMinutes reads a duration as whole minutes ("90") or in Go's
+// duration form ("1h30m")
+func parseDurationMinutes(text string) (int, error) {
+	text = strings.TrimSpace(text)
+	if minutes, err := strconv.Atoi(text); err == nil {
+		return minutes, nil
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, fmt.Errorf("please enter the duration in minutes, or like 1h30m")
+	}
+	return int(math.Round(d.Minutes())), nil
+}
+
+func formatDurationMinutes(minutes int) string {
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+	return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+}
+
 // Save and load functions for the symptom system
 func saveSymptomData() error {
 	file, err := os.Create(symptomFile)
@@ -114,13 +261,513 @@ func loadSymptomDiaryData() error {
 	return nil
 }
 
+// nextSymptomID also skips the IDs of entries whose symptom was deleted, so
+// a new symptom doesn't take over their history
+func nextSymptomID() int {
+	maxID := 0
+	for _, s := range symptomDiary.Symptoms {
+		if s.ID > maxID {
+			maxID = s.ID
+		}
+	}
+	for _, entry := range symptomDiary.Entries {
+		if entry.SymptomID > maxID {
+			maxID = entry.SymptomID
+		}
+	}
+	return maxID + 1
+}
+
+func nextSymptomEntryID() int {
+	maxID := 0
+	for _, entry := range symptomDiary.Entries {
+		if entry.ID > maxID {
+			maxID = entry.ID
+		}
+	}
+	return maxID + 1
+}
+
+func getSymptomByID(id int) *Symptom {
+	for i := range symptomDiary.Symptoms {
+		if symptomDiary.Symptoms[i].ID == id {
+			return &symptomDiary.Symptoms[i]
+		}
+	}
+	return nil
+}
+
+// createSymptom checks and saves a new symptom to track. Settings that do
+// not apply to its tracking type are dropped.
+func createSymptom(draft Symptom) (Symptom, error) {
+	name := strings.TrimSpace(draft.Name)
+	if name == "" {
+		return Symptom{}, fmt.Errorf("please enter a symptom name")
+	}
+	for _, s := range symptomDiary.Symptoms {
+		if strings.EqualFold(s.Name, name) && s.Archived {
+			return Symptom{}, fmt.Errorf("%s is archived; restore it to track it again", s.Name)
+		}
+		if strings.EqualFold(s.Name, name) {
+			return Symptom{}, fmt.Errorf("%s is already being tracked", s.Name)
+		}
+	}
+
+	symptom := Symptom{ID: nextSymptomID(), Name: name, TrackingType: draft.TrackingType}
+	switch draft.TrackingType {
+	case SeverityScale:
+		if draft.ScaleMin >= draft.ScaleMax {
+			return Symptom{}, fmt.Errorf("the scale maximum must be above its minimum")
+		}
+		symptom.ScaleMin = draft.ScaleMin
+		symptom.ScaleMax = draft.ScaleMax
+	case Measurement:
+		symptom.Unit = strings.TrimSpace(draft.Unit)
+		if symptom.Unit == "" {
+			return Symptom{}, fmt.Errorf("please enter the unit the measurement is taken in")
+		}
+		if len(draft.Parts) > 2 {
+			return Symptom{}, fmt.Errorf("a measurement can have at most two values")
+		}
+		for _, part := range draft.Parts {
+			part.Name = strings.TrimSpace(part.Name)
+			if len(draft.Parts) > 1 && part.Name == "" {
+				return Symptom{}, fmt.Errorf("please name both values of the measurement")
+			}
+			if (part.NormalMin != 0 || part.NormalMax != 0) && !part.hasRange() {
+				return Symptom{}, fmt.Errorf("the top of the normal range must be above the bottom")
+			}
+			symptom.Parts = append(symptom.Parts, part)
+		}
+	case YesNo, Counter, Notes, Duration:
+	default:
+		return Symptom{}, fmt.Errorf("unknown tracking type %q", draft.TrackingType)
+	}
+
+	symptomDiary.Symptoms = append(symptomDiary.Symptoms, symptom)
+	if err := saveSymptomData(); err != nil {
+		return symptom, err
+	}
+	notifyDiaryChanged()
+	return symptom, nil
+}
+
+// validateEntry checks an entry holds a value that suits how the symptom
+// is tracked
+func (s Symptom) validateEntry(entry SymptomEntry) error {
+	switch s.TrackingType {
+	case SeverityScale:
+		if entry.SeverityValue < s.ScaleMin || entry.SeverityValue > s.ScaleMax {
+			return fmt.Errorf("severity must be between %d and %d", s.ScaleMin, s.ScaleMax)
+		}
+	case Counter:
+		if entry.CountValue < 0 {
+			return fmt.Errorf("the count cannot be negative")
+		}
+	case Notes:
+		if strings.TrimSpace(entry.Notes) == "" {
+			return fmt.Errorf("please enter some notes")
+		}
+	case Measurement:
+		if len(entry.Measurements) != len(s.measurementParts()) {
+			return fmt.Errorf("%s takes %d values", s.Name, len(s.measurementParts()))
+		}
+		for _, value := range entry.Measurements {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				return fmt.Errorf("please enter a number for each value")
+			}
+		}
+	case Duration:
+		if entry.DurationMinutes <= 0 {
+			return fmt.Errorf("the duration must be at least a minute")
+		}
+	}
+	return nil
+}
+
+// checkSymptomEntry checks an entry can be logged against its symptom and
+// gives it the symptom's current name
+func checkSymptomEntry(entry SymptomEntry) (SymptomEntry, error) {
+	symptom := getSymptomByID(entry.SymptomID)
+	if symptom == nil {
+		return entry, fmt.Errorf("symptom %d not found", entry.SymptomID)
+	}
+	if symptom.Archived {
+		return entry, fmt.Errorf("%s is archived; restore it to log it", symptom.Name)
+	}
+	if err := symptom.validateEntry(entry); err != nil {
+		return entry, err
+	}
+	if _, err := time.Parse("2006-01-02", entry.Date); err != nil {
+		return entry, fmt.Errorf("please enter the date as YYYY-MM-DD")
+	}
+	if entry.Time != "" {
+		if _, err := time.Parse("15:04", entry.Time); err != nil {
+			return entry, fmt.Errorf("please enter the time as HH:MM")
+		}
+	}
+
+	entry.SymptomName = symptom.Name
+	entry.Notes = strings.TrimSpace(entry.Notes)
+	return entry, nil
+}
+
+// logSymptomEntry checks and saves an entry against its symptom, giving it
+// an ID and the symptom's current name
+func logSymptomEntry(entry SymptomEntry) (SymptomEntry, error) {
+	entry, err := checkSymptomEntry(entry)
+	if err != nil {
+		return entry, err
+	}
+	logged, err := appendSymptomEntries([]SymptomEntry{entry})
+	return logged[0], err
+}
+
+// logSymptomEntries saves several entries in one go, such as a check-in.
+// Nothing is saved unless every entry is valid.
+func logSymptomEntries(entries []SymptomEntry) ([]SymptomEntry, error) {
+	checked := make([]SymptomEntry, len(entries))
+	for i, entry := range entries {
+		var err error
+		if checked[i], err = checkSymptomEntry(entry); err != nil {
+			if symptom := getSymptomByID(entry.SymptomID); symptom != nil {
+				return nil, fmt.Errorf("%s: %v", symptom.Name, err)
+			}
+			return nil, err
+		}
+	}
+	return appendSymptomEntries(checked)
+}
+
+// appendSymptomEntries gives checked entries IDs and saves them
+func appendSymptomEntries(entries []SymptomEntry) ([]SymptomEntry, error) {
+	for i := range entries {
+		entries[i].ID = nextSymptomEntryID()
+		symptomDiary.Entries = append(symptomDiary.Entries, entries[i])
+	}
+	if err := saveSymptomDiaryData(); err != nil {
+		return entries, err
+	}
+	notifyDiaryChanged()
+	return entries, nil
+}
+
+// symptomLogCounts counts each symptom's entries on date, so a check-in
+// can show what has already been logged
+func symptomLogCounts(date string) map[int]int {
+	counts := make(map[int]int)
+	for _, entry := range symptomDiary.Entries {
+		if entry.Date == date {
+			counts[entry.SymptomID]++
+		}
+	}
+	return counts
+}
+
+// loggedTodayText describes how often a symptom has been logged today for
+// check-ins
+func loggedTodayText(count int) string {
+	switch count {
+	case 0:
+		return "not logged yet today"
+	case 1:
+		return "logged once today"
+	}
+	return fmt.Sprintf("logged %d times today", count)
+}
+
+func deleteSymptomEntry(id int) error {
+	for i, entry := range symptomDiary.Entries {
+		if entry.ID == id {
+			symptomDiary.Entries = append(symptomDiary.Entries[:i], symptomDiary.Entries[i+1:]...)
+			if err := saveSymptomDiaryData(); err != nil {
+				return err
+			}
+			notifyDiaryChanged()
+			return nil
+		}
+	}
+	return fmt.Errorf("symptom entry %d not found", id)
+}
+
+// activeSymptoms lists the symptoms still being tracked
+func activeSymptoms() []Symptom {
+	var active []Symptom
+	for _, s := range symptomDiary.Symptoms {
+		if !s.Archived {
+			active = append(active, s)
+		}
+	}
+	return active
+}
+
+// setSymptomArchived archives a symptom, or restores an archived one.
+// Archived symptoms cannot be logged but their history stays in the diary.
+func setSymptomArchived(id int, archived bool) error {
+	symptom := getSymptomByID(id)
+	if symptom == nil {
+		return fmt.Errorf("symptom %d not found", id)
+	}
+	symptom.Archived = archived
+	if err := saveSymptomData(); err != nil {
+		return err
+	}
+	notifyDiaryChanged()
+	return nil
+}
+
+// symptomEntryCount counts the diary entries logged against a symptom
+func symptomEntryCount(id int) int {
+	count := 0
+	for _, entry := range symptomDiary.Entries {
+		if entry.SymptomID == id {
+			count++
+		}
+	}
+	return count
+}
+
+// deleteSymptomWithHistory removes a symptom and every entry logged against
+// it, returning how many entries went
+func deleteSymptomWithHistory(id int) (int, error) {
+	if getSymptomByID(id) == nil {
+		return 0, fmt.Errorf("symptom %d not found", id)
+	}
+
+	newSymptoms := make([]Symptom, 0)
+	for _, s := range symptomDiary.Symptoms {
+		if s.ID != id {
+			newSymptoms = append(newSymptoms, s)
+		}
+	}
+	newEntries := make([]SymptomEntry, 0)
+	for _, entry := range symptomDiary.Entries {
+		if entry.SymptomID != id {
+			newEntries = append(newEntries, entry)
+		}
+	}
+	removed := len(symptomDiary.Entries) - len(newEntries)
+
+	symptomDiary.Symptoms = newSymptoms
+	symptomDiary.Entries = newEntries
+	if err := saveSymptomData(); err != nil {
+		return removed, err
+	}
+	if err := saveSymptomDiaryData(); err != nil {
+		return removed, err
+	}
+	notifyDiaryChanged()
+	return removed, nil
+}
+
+// at is when an entry happened, in local time. Entries from before times
+// were recorded only know their day, so they report its midnight and false.
+func (e SymptomEntry) at() (time.Time, bool) {
+	if e.Time != "" {
+		if t, err := time.ParseInLocation("2006-01-02 15:04", e.Date+" "+e.Time, time.Local); err == nil {
+			return t, true
+		}
+	}
+	day, err := time.ParseInLocation("2006-01-02", e.Date, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day, false
+}
+
+func (e SymptomEntry) timeLabel() string {
+	if e.Time == "" {
+		return "--:--"
+	}
+	return e.Time
+}
+
+// sortSymptomEntries puts entries in time order. Day-only entries come
+// first on their day, in the order they were logged.
+func sortSymptomEntries(entries []SymptomEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Date != entries[j].Date {
+			return entries[i].Date < entries[j].Date
+		}
+		return entries[i].Time < entries[j].Time
+	})
+}
+
+// symptomEntriesOn lists the entries logged on date, in time order
+func symptomEntriesOn(date string) []SymptomEntry {
+	var dayEntries []SymptomEntry
+	for _, entry := range symptomDiary.Entries {
+		if entry.Date == date {
+			dayEntries = append(dayEntries, entry)
+		}
+	}
+	sortSymptomEntries(dayEntries)
+	return dayEntries
+}
+
+// symptomEntriesBetween lists the entries from from up to but not including
+// to, in time order. A day-only entry is included when its whole day is.
+func symptomEntriesBetween(from, to time.Time) []SymptomEntry {
+	var matched []SymptomEntry
+	for _, entry := range symptomDiary.Entries {
+		at, timed := entry.at()
+		if at.IsZero() {
+			continue
+		}
+		if !timed {
+			if !at.Before(from) && !at.AddDate(0, 0, 1).After(to) {
+				matched = append(matched, entry)
+			}
+			continue
+		}
+		if !at.Before(from) && at.Before(to) {
+			matched = append(matched, entry)
+		}
+	}
+	sortSymptomEntries(matched)
+	return matched
+}
+
+// startOfHour rounds a local time down to its hour
+func startOfHour(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+}
+
+// symptomHour is one hour of the symptom timeline
+type symptomHour struct {
+	Start   time.Time
+	Entries []SymptomEntry
+}
+
+// symptomTimeline groups the timed entries between from and to by the hour
+// they happened in, leaving out empty hours. Day-only entries cannot be
+// placed in an hour, so they are not included.
+func symptomTimeline(from, to time.Time) []symptomHour {
+	var hours []symptomHour
+	for _, entry := range symptomEntriesBetween(from, to) {
+		at, timed := entry.at()
+		if !timed {
+			continue
+		}
+		start := startOfHour(at)
+		if len(hours) == 0 || !hours[len(hours)-1].Start.Equal(start) {
+			hours = append(hours, symptomHour{Start: start})
+		}
+		hours[len(hours)-1].Entries = append(hours[len(hours)-1].Entries, entry)
+	}
+	return hours
+}
+
+// parseSymptomTime reads when a symptom happened: "YYYY-MM-DD HH:MM", or
+// "HH:MM" for today. Empty means now. Entries can be backdated but not put
+// in the future.
+func parseSymptomTime(text string, now time.Time) (date, clock string, err error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return now.Format("2006-01-02"), now.Format("15:04"), nil
+	}
+
+	var at time.Time
+	if len(text) <= len("15:04") {
+		t, err := time.Parse("15:04", text)
+		if err != nil {
+			return "", "", fmt.Errorf("please enter the time as HH:MM")
+		}
+		at = time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+	} else {
+		at, err = time.ParseInLocation("2006-01-02 15:04", text, now.Location())
+		if err != nil {
+			return "", "", fmt.Errorf("please enter the date and time as YYYY-MM-DD HH:MM")
+		}
+	}
+
+	if at.After(now) {
+		return "", "", fmt.Errorf("symptoms cannot be logged in the future")
+	}
+	return at.Format("2006-01-02"), at.Format("15:04"), nil
+}
+
+// valueText describes the value recorded in an entry
+func (e SymptomEntry) valueText() string {
+	switch getSymptomType(e.SymptomID) {
+	case SeverityScale:
+		if symptom := getSymptomByID(e.SymptomID); symptom != nil {
+			return fmt.Sprintf("Severity: %d (%d-%d)", e.SeverityValue, symptom.ScaleMin, symptom.ScaleMax)
+		}
+		return fmt.Sprintf("Severity: %d", e.SeverityValue)
+	case YesNo:
+		if e.YesNoValue {
+			return "Experienced: yes"
+		}
+		return "Experienced: no"
+	case Counter:
+		return fmt.Sprintf("Count: %d", e.CountValue)
+	case Notes:
+		return "Notes: " + e.Notes
+	case Measurement:
+		symptom := getSymptomByID(e.SymptomID)
+		if symptom == nil {
+			return ""
+		}
+		var values, notes []string
+		parts := symptom.measurementParts()
+		for i, value := range e.Measurements {
+			values = append(values, formatMeasurement(value))
+			if i >= len(parts) {
+				continue
+			}
+			if note := parts[i].rangeNote(value); note != "" {
+				if parts[i].Name != "" {
+					note = parts[i].Name + " " + note
+				}
+				notes = append(notes, note)
+			}
+		}
+		text := "Measurement: " + strings.Join(values, "/") + " " + symptom.Unit
+		if len(notes) > 0 {
+			text += " (" + strings.Join(notes, ", ") + ")"
+		}
+		return text
+	case Duration:
+		return "Duration: " + formatDurationMinutes(e.DurationMinutes)
+	}
+	return e.orphanValueText()
+}
+
+// orphanValueText describes an entry whose symptom was removed before
+// symptoms were archived instead, going by whichever value was recorded
+func (e SymptomEntry) orphanValueText() string {
+	switch {
+	case e.SeverityValue != 0:
+		return fmt.Sprintf("Severity: %d", e.SeverityValue)
+	case e.CountValue != 0:
+		return fmt.Sprintf("Count: %d", e.CountValue)
+	case len(e.Measurements) > 0:
+		var values []string
+		for _, value := range e.Measurements {
+			values = append(values, formatMeasurement(value))
+		}
+		return "Measurement: " + strings.Join(values, "/")
+	case e.DurationMinutes != 0:
+		return "Duration: " + formatDurationMinutes(e.DurationMinutes)
+	case e.Notes != "":
+		return "Notes: " + e.Notes
+	case e.YesNoValue:
+		return "Experienced: yes"
+	}
+	return "No value recorded"
+}
+
 func showSymptomMenu() {
 	fmt.Println("\n=== Symptom Tracker Menu ===")
 	fmt.Println("1. Add symptom to track")
-	fmt.Println("2. Remove symptom from track")
+	fmt.Println("2. Archive or restore a symptom")
 	fmt.Println("3. Add symptom to diary")
 	fmt.Println("4. View Symptom Diary")
-	fmt.Println("5. Return to Main Menu")
+	fmt.Println("5. View Symptom Timeline")
+	fmt.Println("6. Delete a symptom with all its history")
+	fmt.Println("7. Daily check-in")
+	fmt.Println("8. Return to Main Menu")
 	fmt.Print("Choose an option: ")
 }
 
@@ -133,74 +780,92 @@ func addSymptom() {
 	fmt.Println("2. Yes/No")
 	fmt.Println("3. Counter (number of occurrences)")
 	fmt.Println("4. Notes only")
+	fmt.Println("5. Measurement (e.g., temperature, blood pressure)")
+	fmt.Println("6. Duration (minutes)")
 
 	trackingChoice := readInput("Choose tracking method: ")
 
-	// Find max ID
-	maxID := 0
-	for _, s := range symptomDiary.Symptoms {
-		if s.ID > maxID {
-			maxID = s.ID
-		}
-	}
-
-	newSymptom := Symptom{
-		ID:   maxID + 1,
-		Name: name,
-	}
-
+	draft := Symptom{Name: name, TrackingType: SeverityScale, ScaleMin: 1, ScaleMax: 10}
 	switch trackingChoice {
 	case "1":
-		newSymptom.TrackingType = SeverityScale
 		scaleMinStr := readInput("Enter minimum scale value: ")
-		scaleMin, err := strconv.Atoi(scaleMinStr)
+		minValue, err := strconv.Atoi(scaleMinStr)
 		if err != nil {
 			fmt.Println("Invalid minimum value. Using default of 1")
-			scaleMin = 1
+			minValue = 1
 		}
 
 		scaleMaxStr := readInput("Enter maximum scale value: ")
-		scaleMax, err := strconv.Atoi(scaleMaxStr)
+		maxValue, err := strconv.Atoi(scaleMaxStr)
 		if err != nil {
 			fmt.Println("Invalid maximum value. Using default of 10")
-			scaleMax = 10
+			maxValue = 10
 		}
 
-		newSymptom.ScaleMin = scaleMin
-		newSymptom.ScaleMax = scaleMax
+		draft.ScaleMin, draft.ScaleMax = minValue, maxValue
 	case "2":
-		newSymptom.TrackingType = YesNo
+		draft.TrackingType = YesNo
 	case "3":
-		newSymptom.TrackingType = Counter
+		draft.TrackingType = Counter
 	case "4":
-		newSymptom.TrackingType = Notes
+		draft.TrackingType = Notes
+	case "5":
+		draft.TrackingType = Measurement
+		draft.Unit = readInput("Enter the unit (e.g., °C, mmol/L, bpm, mmHg): ")
+
+		partNames := []string{""}
+		if twoValues := readInput("Is it two values, like blood pressure? (y/n): "); twoValues == "y" || twoValues == "Y" {
+			partNames = []string{
+				readInput("Name of the first value (e.g., systolic): "),
+				readInput("Name of the second value (e.g., diastolic): "),
+			}
+		}
+		for _, partName := range partNames {
+			prompt := "Enter normal range (e.g., 36.1-37.2) or press Enter for none: "
+			if partName != "" {
+				prompt = fmt.Sprintf("Enter normal %s range or press Enter for none: ", partName)
+			}
+			low, high, err := parseNormalRange(readInput(prompt))
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			draft.Parts = append(draft.Parts, MeasurementPart{Name: partName, NormalMin: low, NormalMax: high})
+		}
+	case "6":
+		draft.TrackingType = Duration
 	default:
 		fmt.Println("Invalid choice. Defaulting to severity scale (1-10)")
-		newSymptom.TrackingType = SeverityScale
-		newSymptom.ScaleMin = 1
-		newSymptom.ScaleMax = 10
 	}
 
-	symptomDiary.Symptoms = append(symptomDiary.Symptoms, newSymptom)
-	if err := saveSymptomData(); err != nil {
-		log.Printf("Warning: Failed to save symptom data: %v", err)
+	newSymptom, err := createSymptom(draft)
+	if err != nil {
+		fmt.Printf("Could not add symptom: %v\n", err)
+		return
 	}
 
-	fmt.Printf("\nAdded symptom: %s\n", name)
+	fmt.Printf("\nAdded symptom: %s\n", newSymptom.Name)
 	fmt.Printf("Tracking type: %s\n", newSymptom.TrackingType)
 	if newSymptom.TrackingType == SeverityScale {
 		fmt.Printf("Scale range: %d-%d\n", newSymptom.ScaleMin, newSymptom.ScaleMax)
 	}
+	if newSymptom.TrackingType == Measurement {
+		fmt.Printf("Unit: %s\n", newSymptom.Unit)
+		if normal := newSymptom.normalRangeText(); normal != "" {
+			fmt.Printf("Range: %s\n", normal)
+		}
+	}
 }
 
 func addSymptomEntry() {
-	if len(symptomDiary.Symptoms) == 0 {
+	active := activeSymptoms()
+	if len(active) == 0 {
 		fmt.Println("No symptoms configured to track. Please add a symptom first.")
 		return
 	}
 
 	fmt.Println("\n=== Available Symptoms ===")
-	for _, s := range symptomDiary.Symptoms {
+	for _, s := range active {
 		fmt.Printf("%d. %s (%s)\n", s.ID, s.Name, s.TrackingType)
 	}
 
@@ -211,102 +876,244 @@ func addSymptomEntry() {
 		return
 	}
 
-	var symptom Symptom
-	found := false
-	for _, s := range symptomDiary.Symptoms {
-		if s.ID == symptomID {
-			symptom = s
-			found = true
-			break
-		}
+	symptom := getSymptomByID(symptomID)
+	if symptom == nil || symptom.Archived {
+		fmt.Println("Symptom not found")
+		return
 	}
 
-	if !found {
-		fmt.Println("Symptom not found")
+	whenStr := readInput("When? (YYYY-MM-DD HH:MM, HH:MM for today, or press Enter for now): ")
+	date, clock, err := parseSymptomTime(whenStr, time.Now())
+	if err != nil {
+		fmt.Println(err)
 		return
 	}
 
 	entry := SymptomEntry{
-		ID:          len(symptomDiary.Entries) + 1,
-		Date:        time.Now().Format("2006-01-02"),
-		SymptomID:   symptomID,
-		SymptomName: symptom.Name,
+		Date:      date,
+		Time:      clock,
+		SymptomID: symptomID,
 	}
 
+	if err := readSymptomValue(*symptom, &entry); err != nil {
+		if err == errSymptomSkipped {
+			fmt.Println("No value entered")
+		} else {
+			fmt.Println(err)
+		}
+		return
+	}
+
+	if _, err := logSymptomEntry(entry); err != nil {
+		fmt.Printf("Could not add entry: %v\n", err)
+		return
+	}
+
+	fmt.Println("\nSymptom entry added successfully")
+}
+
+// errSymptomSkipped is returned when the first answer for a symptom is left
+// blank
+var errSymptomSkipped = errors.New("symptom skipped")
+
+// readSymptomValue asks for an entry's value in the way the symptom is
+// tracked and checks it
+func readSymptomValue(symptom Symptom, entry *SymptomEntry) error {
 	switch symptom.TrackingType {
 	case SeverityScale:
 		severityStr := readInput(fmt.Sprintf("Enter severity (%d-%d): ", symptom.ScaleMin, symptom.ScaleMax))
+		if severityStr == "" {
+			return errSymptomSkipped
+		}
 		severity, err := strconv.Atoi(severityStr)
-		if err != nil || severity < symptom.ScaleMin || severity > symptom.ScaleMax {
-			fmt.Println("Invalid severity value")
-			return
+		if err != nil {
+			return fmt.Errorf("invalid severity value")
 		}
 		entry.SeverityValue = severity
 
 	case YesNo:
-		response := readInput("Did you experience this symptom today? (y/n): ")
+		response := readInput("Did you experience this symptom? (y/n): ")
+		if response == "" {
+			return errSymptomSkipped
+		}
 		entry.YesNoValue = response == "y" || response == "Y"
 
 	case Counter:
 		countStr := readInput("How many times did this occur? ")
+		if countStr == "" {
+			return errSymptomSkipped
+		}
 		count, err := strconv.Atoi(countStr)
-		if err != nil || count < 0 {
-			fmt.Println("Invalid count value")
-			return
+		if err != nil {
+			return fmt.Errorf("invalid count value")
 		}
 		entry.CountValue = count
 
 	case Notes:
 		entry.Notes = readInput("Enter notes about this symptom: ")
+		if entry.Notes == "" {
+			return errSymptomSkipped
+		}
+
+	case Measurement:
+		entry.Measurements = nil
+		for i, part := range symptom.measurementParts() {
+			prompt := fmt.Sprintf("Enter value (%s): ", symptom.Unit)
+			if part.Name != "" {
+				prompt = fmt.Sprintf("Enter %s (%s): ", part.Name, symptom.Unit)
+			}
+			valueStr := readInput(prompt)
+			if valueStr == "" && i == 0 {
+				return errSymptomSkipped
+			}
+			value, err := strconv.ParseFloat(valueStr, 64)
+			if err != nil {
+				return fmt.Errorf("invalid measurement value")
+			}
+			entry.Measurements = append(entry.Measurements, value)
+		}
+
+	case Duration:
+		durationStr := readInput("How long did it last? (minutes, or e.g. 1h30m): ")
+		if durationStr == "" {
+			return errSymptomSkipped
+		}
+		minutes, err := parseDurationMinutes(durationStr)
+		if err != nil {
+			return err
+		}
+		entry.DurationMinutes = minutes
 	}
 
-	symptomDiary.Entries = append(symptomDiary.Entries, entry)
-	if err := saveSymptomDiaryData(); err != nil {
-		log.Printf("Warning: Failed to save symptom diary data: %v", err)
+	return symptom.validateEntry(*entry)
+}
+
+// dailyCheckIn asks about every active symptom in turn and saves all the
+// answers together at the end. Blank answers skip a symptom.
+func dailyCheckIn() {
+	active := activeSymptoms()
+	if len(active) == 0 {
+		fmt.Println("No symptoms configured to track. Please add a symptom first.")
+		return
 	}
 
-	fmt.Println("\nSymptom entry added successfully")
+	now := time.Now()
+	today := now.Format("2006-01-02")
+	counts := symptomLogCounts(today)
+
+	fmt.Printf("\n=== Daily Check-in for %s ===\n", today)
+	fmt.Println("Press Enter to skip a symptom.")
+
+	var entries []SymptomEntry
+	for i, symptom := range active {
+		fmt.Printf("\n[%d/%d] %s (%s)\n", i+1, len(active), symptom.Name, loggedTodayText(counts[symptom.ID]))
+
+		for {
+			entry := SymptomEntry{Date: today, Time: now.Format("15:04"), SymptomID: symptom.ID}
+			err := readSymptomValue(symptom, &entry)
+			if err == errSymptomSkipped {
+				fmt.Println("Skipped")
+				break
+			}
+			if err != nil {
+				fmt.Printf("%v. Please try again or press Enter to skip.\n", err)
+				continue
+			}
+			entries = append(entries, entry)
+			break
+		}
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("\nNothing to save")
+		return
+	}
+	if _, err := logSymptomEntries(entries); err != nil {
+		fmt.Printf("Could not save check-in: %v\n", err)
+		return
+	}
+	fmt.Printf("\nCheck-in saved: %d of %d symptoms logged\n", len(entries), len(active))
 }
 
-func removeSymptom() {
+// archiveSymptom archives a symptom so it is no longer logged, or restores
+// an archived one. Its diary entries are kept either way.
+func archiveSymptom() {
 	if len(symptomDiary.Symptoms) == 0 {
-		fmt.Println("No symptoms configured to remove")
+		fmt.Println("No symptoms configured to archive")
 		return
 	}
 
 	fmt.Println("\n=== Current Symptoms ===")
 	for _, s := range symptomDiary.Symptoms {
-		fmt.Printf("%d. %s\n", s.ID, s.Name)
+		if s.Archived {
+			fmt.Printf("%d. %s (archived)\n", s.ID, s.Name)
+		} else {
+			fmt.Printf("%d. %s\n", s.ID, s.Name)
+		}
 	}
 
-	idStr := readInput("Enter symptom ID to remove: ")
+	idStr := readInput("Enter symptom ID to archive or restore: ")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
 		fmt.Println("Invalid symptom ID.")
 		return
 	}
 
-	newSymptoms := make([]Symptom, 0)
-	found := false
+	symptom := getSymptomByID(id)
+	if symptom == nil {
+		fmt.Println("Symptom not found")
+		return
+	}
+	archived := !symptom.Archived
+	if err := setSymptomArchived(id, archived); err != nil {
+		log.Printf("Warning: Failed to save symptom data: %v", err)
+	}
+
+	if archived {
+		fmt.Printf("%s archived. Its history stays in the diary.\n", symptom.Name)
+	} else {
+		fmt.Printf("%s restored\n", symptom.Name)
+	}
+}
+
+// deleteSymptomHistory removes a symptom and all of its entries once the
+// user has confirmed by typing its name
+func deleteSymptomHistory() {
+	if len(symptomDiary.Symptoms) == 0 {
+		fmt.Println("No symptoms configured to delete")
+		return
+	}
+
+	fmt.Println("\n=== Current Symptoms ===")
 	for _, s := range symptomDiary.Symptoms {
-		if s.ID != id {
-			newSymptoms = append(newSymptoms, s)
-		} else {
-			found = true
-		}
+		fmt.Printf("%d. %s (%d entries)\n", s.ID, s.Name, symptomEntryCount(s.ID))
+	}
+
+	idStr := readInput("Enter symptom ID to delete with all its history: ")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		fmt.Println("Invalid symptom ID.")
+		return
 	}
 
-	if !found {
+	symptom := getSymptomByID(id)
+	if symptom == nil {
 		fmt.Println("Symptom not found")
 		return
 	}
+	name := symptom.Name
 
-	symptomDiary.Symptoms = newSymptoms
-	if err := saveSymptomData(); err != nil {
-		log.Printf("Warning: Failed to save symptom data: %v", err)
+	fmt.Printf("This permanently deletes %s and its %d diary entries.\n", name, symptomEntryCount(id))
+	if confirm := readInput("Type the symptom name to confirm: "); !strings.EqualFold(strings.TrimSpace(confirm), name) {
+		fmt.Println("Delete cancelled")
+		return
 	}
 
-	fmt.Println("Symptom removed successfully")
+	removed, err := deleteSymptomWithHistory(id)
+	if err != nil {
+		log.Printf("Warning: Failed to save symptom data: %v", err)
+	}
+	fmt.Printf("Deleted %s and %d entries\n", name, removed)
 }
 
 func viewSymptomDiary() {
@@ -316,12 +1123,7 @@ func viewSymptomDiary() {
 		dateStr = time.Now().Format("2006-01-02")
 	}
 
-	var dayEntries []SymptomEntry
-	for _, entry := range symptomDiary.Entries {
-		if entry.Date == dateStr {
-			dayEntries = append(dayEntries, entry)
-		}
-	}
+	dayEntries := symptomEntriesOn(dateStr)
 
 	if len(dayEntries) == 0 {
 		fmt.Printf("No entries found for %s\n", dateStr)
@@ -331,17 +1133,54 @@ func viewSymptomDiary() {
 	fmt.Printf("\nSymptom Diary for %s:\n", dateStr)
 	fmt.Println("----------------------------------------")
 	for _, entry := range dayEntries {
-		fmt.Printf("Symptom: %s\n", entry.SymptomName)
+		fmt.Printf("%s  Symptom: %s\n", entry.timeLabel(), entry.SymptomName)
+		if value := entry.valueText(); value != "" {
+			fmt.Printf("  %s\n", value)
+		}
+	}
+	fmt.Println("----------------------------------------")
+}
+
+// viewSymptomTimeline lists symptoms hour by hour from a starting hour
+func viewSymptomTimeline() {
+	fmt.Println("\n=== Symptom Timeline ===")
+	startStr := readInput("Start (YYYY-MM-DD HH) or press Enter for the last 24 hours: ")
+	hoursStr := readInput("Number of hours to show (default 24): ")
+
+	hours := 24
+	if hoursStr != "" {
+		n, err := strconv.Atoi(hoursStr)
+		if err != nil || n <= 0 {
+			fmt.Println("Invalid number of hours")
+			return
+		}
+		hours = n
+	}
 
-		switch getSymptomType(entry.SymptomID) {
-		case SeverityScale:
-			fmt.Printf("  Severity: %d\n", entry.SeverityValue)
-		case YesNo:
-			fmt.Printf("  Experienced: %v\n", entry.YesNoValue)
-		case Counter:
-			fmt.Printf("  Count: %d\n", entry.CountValue)
-		case Notes:
-			fmt.Printf("  Notes: %s\n", entry.Notes)
+	var from time.Time
+	if startStr == "" {
+		from = startOfHour(time.Now()).Add(-time.Duration(hours-1) * time.Hour)
+	} else {
+		start, err := time.ParseInLocation("2006-01-02 15", startStr, time.Local)
+		if err != nil {
+			fmt.Println("Invalid start. Please use YYYY-MM-DD HH")
+			return
+		}
+		from = start
+	}
+	to := from.Add(time.Duration(hours) * time.Hour)
+
+	timeline := symptomTimeline(from, to)
+	if len(timeline) == 0 {
+		fmt.Printf("No timed entries between %s and %s\n", from.Format("2006-01-02 15:04"), to.Format("2006-01-02 15:04"))
+		return
+	}
+
+	fmt.Println("----------------------------------------")
+	for _, hour := range timeline {
+		fmt.Printf("%s\n", hour.Start.Format("2006-01-02 15:00"))
+		for _, entry := range hour.Entries {
+			fmt.Printf("  %s %s: %s\n", entry.Time, entry.SymptomName, entry.valueText())
 		}
 	}
 	fmt.Println("----------------------------------------")
@@ -365,12 +1204,18 @@ func HandleSymptomMenu() {
 		case "1":
 			addSymptom()
 		case "2":
-			removeSymptom()
+			archiveSymptom()
 		case "3":
 			addSymptomEntry()
 		case "4":
 			viewSymptomDiary()
 		case "5":
+			viewSymptomTimeline()
+		case "6":
+			deleteSymptomHistory()
+		case "7":
+			dailyCheckIn()
+		case "8":
 			return
 		default:
 			fmt.Println("Invalid choice")
@@ -0,0 +1,738 @@
+package main
+
+import (
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// symptomInputWidget builds the input that suits how a symptom is tracked,
+// with a function that reads what was entered into an entry
+func symptomInputWidget(s Symptom) (fyne.CanvasObject, func(*SymptomEntry) error) {
+	switch s.TrackingType {
+	case SeverityScale:
+		var levels []string
+		for level := s.ScaleMin; level <= s.ScaleMax; level++ {
+			levels = append(levels, strconv.Itoa(level))
+		}
+		severitySelect := widget.NewSelect(levels, nil)
+		severitySelect.PlaceHolder = fmt.Sprintf("Severity (%d-%d)", s.ScaleMin, s.ScaleMax)
+		return severitySelect, func(entry *SymptomEntry) error {
+			severity, err := strconv.Atoi(severitySelect.Selected)
+			if err != nil {
+				return fmt.Errorf("please choose a severity")
+			}
+			entry.SeverityValue = severity
+			return nil
+		}
+
+	case YesNo:
+		answer := widget.NewRadioGroup([]string{"Yes", "No"}, nil)
+		answer.Horizontal = true
+		return answer, func(entry *SymptomEntry) error {
+			if answer.Selected == "" {
+				return fmt.Errorf("please answer yes or no")
+			}
+			entry.YesNoValue = answer.Selected == "Yes"
+			return nil
+		}
+
+	case Counter:
+		countEntry := widget.NewEntry()
+		countEntry.SetPlaceHolder("Number of times")
+		return countEntry, func(entry *SymptomEntry) error {
+			count, err := strconv.Atoi(strings.TrimSpace(countEntry.Text))
+			if err != nil {
+				return fmt.Errorf("please enter how many times it happened")
+			}
+			entry.CountValue = count
+			return nil
+		}
+
+	case Measurement:
+		var valueEntries []*widget.Entry
+		row := container.NewGridWithColumns(len(s.measurementParts()))
+		for _, part := range s.measurementParts() {
+			valueEntry := widget.NewEntry()
+			if part.Name != "" {
+				valueEntry.SetPlaceHolder(fmt.Sprintf("%s (%s)", part.Name, s.Unit))
+			} else {
+				valueEntry.SetPlaceHolder(s.Unit)
+			}
+			valueEntries = append(valueEntries, valueEntry)
+			row.Add(valueEntry)
+		}
+		return row, func(entry *SymptomEntry) error {
+			entry.Measurements = nil
+			for _, valueEntry := range valueEntries {
+				value, err := strconv.ParseFloat(strings.TrimSpace(valueEntry.Text), 64)
+				if err != nil {
+					return fmt.Errorf("please enter a number for each value")
+				}
+				entry.Measurements = append(entry.Measurements, value)
+			}
+			return nil
+		}
+
+	case Duration:
+		durationEntry := widget.NewEntry()
+		durationEntry.SetPlaceHolder("Minutes, or e.g. 1h30m")
+		return durationEntry, func(entry *SymptomEntry) error {
+			minutes, err := parseDurationMinutes(durationEntry.Text)
+			if err != nil {
+				return err
+			}
+			entry.DurationMinutes = minutes
+			return nil
+		}
+
+	default:
+		notesEntry := widget.NewMultiLineEntry()
+		notesEntry.SetPlaceHolder("Notes about this symptom")
+		return notesEntry, func(entry *SymptomEntry) error {
+			entry.Notes = notesEntry.Text
+			return nil
+		}
+	}
+}
+
+// symptomLabel names a symptom with how it is tracked
+func symptomLabel(s Symptom) string {
+	switch s.TrackingType {
+	case SeverityScale:
+		return fmt.Sprintf("%s (%s %d-%d)", s.Name, s.TrackingType.label(), s.ScaleMin, s.ScaleMax)
+	case Measurement:
+		if normal := s.normalRangeText(); normal != "" {
+			return fmt.Sprintf("%s (%s, %s)", s.Name, s.TrackingType.label(), normal)
+		}
+		return fmt.Sprintf("%s (%s in %s)", s.Name, s.TrackingType.label(), s.Unit)
+	}
+	return fmt.Sprintf("%s (%s)", s.Name, s.TrackingType.label())
+}
+
+// measurementPreset fills in the measurement settings for common readings
+type measurementPreset struct {
+	name  string
+	unit  string
+	parts []MeasurementPart
+}
+
+var measurementPresets = []measurementPreset{
+	{name: "Temperature", unit: "°C", parts: []MeasurementPart{{NormalMin: 36.1, NormalMax: 37.2}}},
+	{name: "Blood glucose", unit: "mmol/L", parts: []MeasurementPart{{NormalMin: 4, NormalMax: 7.8}}},
+	{name: "Resting heart rate", unit: "bpm", parts: []MeasurementPart{{NormalMin: 60, NormalMax: 100}}},
+	{name: "Blood pressure", unit: "mmHg", parts: []MeasurementPart{
+		{Name: "systolic", NormalMin: 90, NormalMax: 120},
+		{Name: "diastolic", NormalMin: 60, NormalMax: 80},
+	}},
+}
+
+// showSymptomsWindow defines new symptoms to track and removes old ones
+func showSymptomsWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Symptoms")
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("e.g. Headache")
+
+	var typeLabels []string
+	for _, t := range trackingTypes {
+		typeLabels = append(typeLabels, t.label())
+	}
+
+	scaleMinEntry := widget.NewEntry()
+	scaleMinEntry.SetText("1")
+	scaleMaxEntry := widget.NewEntry()
+	scaleMaxEntry.SetText("10")
+	scaleForm := widget.NewForm(
+		widget.NewFormItem("Scale (min, max)", container.NewGridWithColumns(2, scaleMinEntry, scaleMaxEntry)),
+	)
+
+	unitEntry := widget.NewEntry()
+	unitEntry.SetPlaceHolder("e.g. °C")
+	firstNameEntry := widget.NewEntry()
+	firstNameEntry.SetPlaceHolder("Optional, e.g. systolic")
+	firstRangeEntry := widget.NewEntry()
+	firstRangeEntry.SetPlaceHolder("Optional, e.g. 36.1-37.2")
+	secondNameEntry := widget.NewEntry()
+	secondNameEntry.SetPlaceHolder("e.g. diastolic")
+	secondRangeEntry := widget.NewEntry()
+	secondRangeEntry.SetPlaceHolder("Optional, e.g. 60-80")
+	secondForm := widget.NewForm(
+		widget.NewFormItem("Second value", secondNameEntry),
+		widget.NewFormItem("Normal range", secondRangeEntry),
+	)
+	secondForm.Hide()
+	twoValuesCheck := widget.NewCheck("Two values, like blood pressure", func(checked bool) {
+		if checked {
+			secondForm.Show()
+		} else {
+			secondForm.Hide()
+		}
+	})
+
+	var presetNames []string
+	for _, preset := range measurementPresets {
+		presetNames = append(presetNames, preset.name)
+	}
+	presetSelect := widget.NewSelect(presetNames, func(selected string) {
+		for _, preset := range measurementPresets {
+			if preset.name != selected {
+				continue
+			}
+			if strings.TrimSpace(nameEntry.Text) == "" {
+				nameEntry.SetText(preset.name)
+			}
+			unitEntry.SetText(preset.unit)
+			firstNameEntry.SetText(preset.parts[0].Name)
+			firstRangeEntry.SetText(preset.parts[0].rangeText())
+			twoValuesCheck.SetChecked(len(preset.parts) > 1)
+			if len(preset.parts) > 1 {
+				secondNameEntry.SetText(preset.parts[1].Name)
+				secondRangeEntry.SetText(preset.parts[1].rangeText())
+			}
+		}
+	})
+	presetSelect.PlaceHolder = "Choose a common measurement"
+
+	measurementBox := container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem("Preset", presetSelect),
+			widget.NewFormItem("Unit", unitEntry),
+			widget.NewFormItem("Value name", firstNameEntry),
+			widget.NewFormItem("Normal range", firstRangeEntry),
+			widget.NewFormItem("", twoValuesCheck),
+		),
+		secondForm,
+	)
+	measurementBox.Hide()
+
+	// The scale only applies to severity, the rest to measurements
+	typeSelect := widget.NewSelect(typeLabels, func(selected string) {
+		if selected == SeverityScale.label() {
+			scaleForm.Show()
+		} else {
+			scaleForm.Hide()
+		}
+		if selected == Measurement.label() {
+			measurementBox.Show()
+		} else {
+			measurementBox.Hide()
+		}
+	})
+
+	statusLabel := widget.NewLabel("")
+
+	symptomsList := widget.NewList(
+		func() int { return len(symptomDiary.Symptoms) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			symptom := symptomDiary.Symptoms[id]
+			label := symptomLabel(symptom)
+			if symptom.Archived {
+				label += " - archived"
+			}
+			item.(*widget.Label).SetText(label)
+		},
+	)
+
+	selectedID := 0
+	archiveBtn := widget.NewButton("Archive", nil)
+	deleteBtn := widget.NewButton("Delete with History...", nil)
+
+	// The archive button restores when an archived symptom is selected
+	showSelection := func() {
+		symptom := getSymptomByID(selectedID)
+		if symptom != nil && symptom.Archived {
+			archiveBtn.SetText("Restore")
+		} else {
+			archiveBtn.SetText("Archive")
+		}
+	}
+
+	symptomsList.OnSelected = func(id widget.ListItemID) {
+		selectedID = symptomDiary.Symptoms[id].ID
+		showSelection()
+	}
+
+	clearSelection := func() {
+		selectedID = 0
+		symptomsList.UnselectAll()
+		symptomsList.Refresh()
+		showSelection()
+	}
+
+	archiveBtn.OnTapped = func() {
+		symptom := getSymptomByID(selectedID)
+		if symptom == nil {
+			statusLabel.SetText("Please select a symptom")
+			return
+		}
+		name, archived := symptom.Name, !symptom.Archived
+		if err := setSymptomArchived(selectedID, archived); err != nil {
+			statusLabel.SetText("Error saving symptoms: " + err.Error())
+			return
+		}
+		if archived {
+			statusLabel.SetText(name + " archived; its history is kept")
+		} else {
+			statusLabel.SetText(name + " restored")
+		}
+		symptomsList.Refresh()
+		showSelection()
+	}
+
+	deleteBtn.OnTapped = func() {
+		symptom := getSymptomByID(selectedID)
+		if symptom == nil {
+			statusLabel.SetText("Please select a symptom")
+			return
+		}
+		name := symptom.Name
+		message := fmt.Sprintf("Permanently delete %s and all %d of its diary entries?\nArchive it instead to keep its history.",
+			name, symptomEntryCount(selectedID))
+		dialog.ShowConfirm("Delete "+name, message, func(ok bool) {
+			if !ok {
+				return
+			}
+			removed, err := deleteSymptomWithHistory(selectedID)
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			clearSelection()
+			statusLabel.SetText(fmt.Sprintf("Deleted %s and %d entries", name, removed))
+		}, window)
+	}
+
+	addBtn := widget.NewButton("Add Symptom", func() {
+		var trackingType TrackingType
+		for _, t := range trackingTypes {
+			if t.label() == typeSelect.Selected {
+				trackingType = t
+			}
+		}
+		if trackingType == "" {
+			statusLabel.SetText("Please choose how to track the symptom")
+			return
+		}
+
+		draft := Symptom{Name: nameEntry.Text, TrackingType: trackingType}
+		switch trackingType {
+		case SeverityScale:
+			var errMin, errMax error
+			draft.ScaleMin, errMin = strconv.Atoi(strings.TrimSpace(scaleMinEntry.Text))
+			draft.ScaleMax, errMax = strconv.Atoi(strings.TrimSpace(scaleMaxEntry.Text))
+			if errMin != nil || errMax != nil {
+				statusLabel.SetText("Please enter whole numbers for the scale")
+				return
+			}
+		case Measurement:
+			draft.Unit = unitEntry.Text
+			parts := [][2]string{{firstNameEntry.Text, firstRangeEntry.Text}}
+			if twoValuesCheck.Checked {
+				parts = append(parts, [2]string{secondNameEntry.Text, secondRangeEntry.Text})
+			}
+			for _, part := range parts {
+				low, high, err := parseNormalRange(part[1])
+				if err != nil {
+					statusLabel.SetText(err.Error())
+					return
+				}
+				draft.Parts = append(draft.Parts, MeasurementPart{Name: part[0], NormalMin: low, NormalMax: high})
+			}
+		}
+
+		symptom, err := createSymptom(draft)
+		if err != nil {
+			statusLabel.SetText(err.Error())
+			return
+		}
+		statusLabel.SetText("Added " + symptomLabel(symptom))
+		nameEntry.SetText("")
+		symptomsList.Refresh()
+	})
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Name", nameEntry),
+		widget.NewFormItem("Track as", typeSelect),
+	)
+
+	listScroll := container.NewScroll(symptomsList)
+	listScroll.SetMinSize(fyne.NewSize(360, 200))
+
+	content := container.NewVBox(
+		widget.NewLabelWithStyle("Symptoms", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		form,
+		scaleForm,
+		measurementBox,
+		addBtn,
+		statusLabel,
+		widget.NewLabel("Symptoms (select one to archive or delete)"),
+		listScroll,
+		container.NewGridWithColumns(2, archiveBtn, deleteBtn),
+		backBtn,
+	)
+
+	typeSelect.SetSelected(SeverityScale.label())
+	window.SetContent(content)
+	window.Resize(fyne.NewSize(460, 750))
+	window.Show()
+	return window
+}
+
+// showLogSymptomWindow records an entry for one symptom
+func showLogSymptomWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Log Symptom")
+
+	dateEntry := widget.NewEntry()
+	dateEntry.SetText(time.Now().Format("2006-01-02"))
+	calendarBtn := widget.NewButton("📅", func() {
+		initial, err := time.Parse("2006-01-02", dateEntry.Text)
+		if err != nil {
+			initial = time.Now()
+		}
+		showDatePicker(window, initial, func(picked time.Time) {
+			dateEntry.SetText(picked.Format("2006-01-02"))
+		})
+	})
+
+	// Defaults to now; change it to backdate an entry
+	timeEntry := widget.NewEntry()
+	timeEntry.SetText(time.Now().Format("15:04"))
+	timeEntry.SetPlaceHolder("HH:MM")
+
+	statusLabel := widget.NewLabel("")
+	inputArea := container.NewMax()
+	var readValue func(*SymptomEntry) error
+	var selected *Symptom
+
+	symptomSelect := widget.NewSelect(nil, func(name string) {
+		selected = nil
+		for _, s := range activeSymptoms() {
+			if s.Name == name {
+				symptom := s
+				selected = &symptom
+			}
+		}
+		if selected == nil {
+			inputArea.Objects = nil
+			inputArea.Refresh()
+			return
+		}
+		var input fyne.CanvasObject
+		input, readValue = symptomInputWidget(*selected)
+		inputArea.Objects = []fyne.CanvasObject{input}
+		inputArea.Refresh()
+	})
+
+	// Offer the symptoms active now, dropping the selected one if it has
+	// been archived or deleted
+	refreshSymptoms := func() {
+		var names []string
+		stillActive := false
+		for _, s := range activeSymptoms() {
+			names = append(names, s.Name)
+			if selected != nil && s.ID == selected.ID {
+				stillActive = true
+			}
+		}
+		symptomSelect.Options = names
+		symptomSelect.Refresh()
+		if selected != nil && !stillActive {
+			symptomSelect.ClearSelected()
+			selected = nil
+			inputArea.Objects = nil
+			inputArea.Refresh()
+		}
+		if len(names) == 0 {
+			statusLabel.SetText("No symptoms to log yet. Add one from the Symptoms window.")
+		} else if strings.HasPrefix(statusLabel.Text, "No symptoms to log") {
+			statusLabel.SetText("")
+		}
+	}
+	refreshSymptoms()
+
+	// Symptoms added, archived or deleted in other windows show up here
+	removeListener := addDiaryListener(refreshSymptoms)
+	window.SetOnClosed(removeListener)
+
+	logBtn := widget.NewButton("Log Entry", func() {
+		if selected == nil {
+			statusLabel.SetText("Please choose a symptom")
+			return
+		}
+		if _, err := time.Parse("2006-01-02", strings.TrimSpace(dateEntry.Text)); err != nil {
+			statusLabel.SetText("Please enter the date as YYYY-MM-DD")
+			return
+		}
+		date, clock, err := parseSymptomTime(strings.TrimSpace(dateEntry.Text)+" "+strings.TrimSpace(timeEntry.Text), time.Now())
+		if err != nil {
+			statusLabel.SetText(err.Error())
+			return
+		}
+
+		entry := SymptomEntry{Date: date, Time: clock, SymptomID: selected.ID}
+		if err := readValue(&entry); err != nil {
+			statusLabel.SetText(err.Error())
+			return
+		}
+		entry, err = logSymptomEntry(entry)
+		if err != nil {
+			statusLabel.SetText(err.Error())
+			return
+		}
+		statusLabel.SetText(fmt.Sprintf("Logged %s on %s at %s", entry.SymptomName, entry.Date, entry.Time))
+
+		// Start the same symptom afresh for the next entry
+		symptomSelect.OnChanged(selected.Name)
+	})
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Date", container.NewBorder(nil, nil, nil, calendarBtn, dateEntry)),
+		widget.NewFormItem("Time", timeEntry),
+		widget.NewFormItem("Symptom", symptomSelect),
+		widget.NewFormItem("Value", inputArea),
+	)
+
+	content := container.NewVBox(
+		widget.NewLabelWithStyle("Log Symptom", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		form,
+		logBtn,
+		statusLabel,
+		backBtn,
+	)
+
+	window.SetContent(content)
+	window.Resize(fyne.NewSize(420, 350))
+	window.Show()
+	return window
+}
+
+// showSymptomDiaryWindow browses the symptom diary a day at a time
+func showSymptomDiaryWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Symptom Diary")
+
+	dateEntry := widget.NewEntry()
+	dateEntry.SetText(time.Now().Format("2006-01-02"))
+	calendarBtn := widget.NewButton("📅", func() {
+		initial, err := time.Parse("2006-01-02", dateEntry.Text)
+		if err != nil {
+			initial = time.Now()
+		}
+		showDatePicker(window, initial, func(picked time.Time) {
+			dateEntry.SetText(picked.Format("2006-01-02"))
+		})
+	})
+
+	shiftDay := func(days int) {
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(dateEntry.Text))
+		if err != nil {
+			date = time.Now()
+		}
+		dateEntry.SetText(date.AddDate(0, 0, days).Format("2006-01-02"))
+	}
+	prevBtn := widget.NewButton("◀", func() {
+		shiftDay(-1)
+	})
+	nextBtn := widget.NewButton("▶", func() {
+		shiftDay(1)
+	})
+
+	summaryLabel := widget.NewLabel("")
+	statusLabel := widget.NewLabel("")
+
+	var dayEntries []SymptomEntry
+	entriesList := widget.NewList(
+		func() int { return len(dayEntries) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			entry := dayEntries[id]
+			item.(*widget.Label).SetText(entry.timeLabel() + "  " + entry.SymptomName + ": " + entry.valueText())
+		},
+	)
+
+	refresh := func() {
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(dateEntry.Text))
+		if err != nil {
+			summaryLabel.SetText("Please enter the date as YYYY-MM-DD")
+			return
+		}
+		dateStr := date.Format("2006-01-02")
+		dayEntries = symptomEntriesOn(dateStr)
+		entriesList.UnselectAll()
+		entriesList.Refresh()
+		if len(dayEntries) == 0 {
+			summaryLabel.SetText("No entries found for " + dateStr)
+		} else {
+			summaryLabel.SetText(fmt.Sprintf("%d entries on %s", len(dayEntries), dateStr))
+		}
+	}
+	dateEntry.OnChanged = func(string) {
+		refresh()
+	}
+
+	entriesList.OnSelected = func(id widget.ListItemID) {
+		entry := dayEntries[id]
+		dialog.ShowConfirm("Delete Entry", fmt.Sprintf("Delete %s %s: %s?", entry.timeLabel(), entry.SymptomName, entry.valueText()), func(ok bool) {
+			if ok {
+				if err := deleteSymptomEntry(entry.ID); err != nil {
+					statusLabel.SetText("Error saving symptom diary: " + err.Error())
+				}
+			}
+			refresh()
+		}, window)
+	}
+
+	// Entries logged from other windows show up here too
+	removeListener := addDiaryListener(refresh)
+	window.SetOnClosed(removeListener)
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	content := container.NewBorder(
+		container.NewVBox(
+			widget.NewLabelWithStyle("Symptom Diary", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+			container.NewBorder(nil, nil, prevBtn, container.NewHBox(calendarBtn, nextBtn), dateEntry),
+			summaryLabel,
+			widget.NewLabel("Entries in time order (tap to delete)"),
+		),
+		container.NewVBox(statusLabel, backBtn),
+		nil, nil,
+		entriesList,
+	)
+
+	refresh()
+	window.SetContent(content)
+	window.Resize(fyne.NewSize(420, 500))
+	window.Show()
+	return window
+}
+
+// showSymptomCheckInWindow asks about every active symptom at once and
+// saves all the answers together
+func showSymptomCheckInWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Daily Check-in")
+
+	headingLabel := widget.NewLabelWithStyle("", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	statusLabel := widget.NewLabel("")
+	rowsBox := container.NewVBox()
+
+	// checkInRow is one symptom's question
+	type checkInRow struct {
+		symptom   Symptom
+		skipCheck *widget.Check
+		readValue func(*SymptomEntry) error
+	}
+	var rows []checkInRow
+	var shownSymptoms []Symptom
+
+	// Symptoms already logged today start skipped
+	showRows := func() {
+		today := time.Now().Format("2006-01-02")
+		headingLabel.SetText("Daily Check-in for " + today)
+		counts := symptomLogCounts(today)
+
+		rows = nil
+		rowsBox.Objects = nil
+		shownSymptoms = activeSymptoms()
+		for _, symptom := range shownSymptoms {
+			input, readValue := symptomInputWidget(symptom)
+			skipCheck := widget.NewCheck("Skip", nil)
+			skipCheck.SetChecked(counts[symptom.ID] > 0)
+			rows = append(rows, checkInRow{symptom: symptom, skipCheck: skipCheck, readValue: readValue})
+
+			rowsBox.Add(container.NewBorder(
+				container.NewBorder(nil, nil,
+					widget.NewLabelWithStyle(symptom.Name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+					skipCheck,
+					widget.NewLabel(loggedTodayText(counts[symptom.ID])),
+				),
+				nil, nil, nil,
+				input,
+			))
+			rowsBox.Add(widget.NewSeparator())
+		}
+		if len(rows) == 0 {
+			rowsBox.Add(widget.NewLabel("No symptoms to check in on yet. Add one from the Symptoms window."))
+		}
+		rowsBox.Refresh()
+	}
+
+	// Rebuild the rows when symptoms are added, changed, archived or deleted
+	// elsewhere; other diary changes leave answers being typed alone
+	removeListener := addDiaryListener(func() {
+		if !reflect.DeepEqual(activeSymptoms(), shownSymptoms) {
+			showRows()
+		}
+	})
+	window.SetOnClosed(removeListener)
+
+	saveBtn := widget.NewButton("Save Check-in", func() {
+		now := time.Now()
+		var entries []SymptomEntry
+		for _, row := range rows {
+			if row.skipCheck.Checked {
+				continue
+			}
+			entry := SymptomEntry{Date: now.Format("2006-01-02"), Time: now.Format("15:04"), SymptomID: row.symptom.ID}
+			if err := row.readValue(&entry); err != nil {
+				statusLabel.SetText(fmt.Sprintf("%s: %v, or tick Skip", row.symptom.Name, err))
+				return
+			}
+			entries = append(entries, entry)
+		}
+		if len(entries) == 0 {
+			statusLabel.SetText("Nothing to save; every symptom is skipped")
+			return
+		}
+
+		if _, err := logSymptomEntries(entries); err != nil {
+			statusLabel.SetText(err.Error())
+			return
+		}
+		statusLabel.SetText(fmt.Sprintf("Check-in saved: %d logged, %d skipped", len(entries), len(rows)-len(entries)))
+		showRows()
+	})
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	rowsScroll := container.NewVScroll(rowsBox)
+	rowsScroll.SetMinSize(fyne.NewSize(440, 400))
+
+	content := container.NewVBox(
+		headingLabel,
+		widget.NewLabel("Answer each symptom, or tick Skip to leave it out"),
+		rowsScroll,
+		saveBtn,
+		statusLabel,
+		backBtn,
+	)
+
+	showRows()
+	window.SetContent(content)
+	window.Resize(fyne.NewSize(480, 620))
+	window.Show()
+	return window
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// uncategorised labels foods with no category in breakdowns
+const uncategorised = "uncategorised"
+
+// normalizeTag makes tags and categories compare the same however they
+// were typed: lower case, single spaced
+func normalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), " ")
+}
+
+// parseTags reads a comma separated list of tags, dropping blanks and
+// repeats
+func parseTags(text string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(text, ",") {
+		tag := normalizeTag(part)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func formatTags(tags []string) string {
+	return strings.Join(tags, ", ")
+}
+
+// foodGroups are the category and tags a food belongs to. A group is
+// either, so "dairy" can be a category on one food and a tag on another.
+func (f Food) foodGroups() []string {
+	var groups []string
+	if category := normalizeTag(f.Category); category != "" {
+		groups = append(groups, category)
+	}
+	for _, tag := range f.Tags {
+		if tag = normalizeTag(tag); tag != "" && tag != normalizeTag(f.Category) {
+			groups = append(groups, tag)
+		}
+	}
+	return groups
+}
+
+// inGroup reports whether the food's category or any of its tags is group
+func (f Food) inGroup(group string) bool {
+	group = normalizeTag(group)
+	for _, g := range f.foodGroups() {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// inAllGroups reports whether the food is in every one of groups
+func inAllGroups(f Food, groups []string) bool {
+	for _, group := range groups {
+		if !f.inGroup(group) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f Food) categoryLabel() string {
+	if category := normalizeTag(f.Category); category != "" {
+		return category
+	}
+	return uncategorised
+}
+
+// allCategories lists the categories in use, sorted
+func allCategories() []string {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, food := range foods {
+		if category := normalizeTag(food.Category); category != "" && !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// allGroups lists every category and tag in use, sorted
+func allGroups() []string {
+	seen := make(map[string]bool)
+	var groups []string
+	for _, food := range foods {
+		for _, group := range food.foodGroups() {
+			if !seen[group] {
+				seen[group] = true
+				groups = append(groups, group)
+			}
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// entryGroups are the groups of the food an entry was logged against. An
+// entry whose food has been deleted belongs to no group.
+func entryGroups(entry DiaryEntry) []string {
+	food := getFoodByID(entry.FoodID)
+	if food == nil {
+		return nil
+	}
+	return food.foodGroups()
+}
+
+// entriesInGroup picks the entries whose food is in group, so that for
+// example everything tagged "dairy" can be analysed as one
+func entriesInGroup(entries []DiaryEntry, group string) []DiaryEntry {
+	var matched []DiaryEntry
+	for _, entry := range entries {
+		if food := getFoodByID(entry.FoodID); food != nil && food.inGroup(group) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+// groupTotal is what was eaten from one group
+type groupTotal struct {
+	Group    string
+	Calories float64
+	Cost     float64
+	Entries  int
+}
+
+// totalsByGroup adds up entries by the groups groupOf puts each one in.
+// An entry in several groups counts towards each. Largest calories first.
+func totalsByGroup(entries []DiaryEntry, groupOf func(DiaryEntry) []string) []groupTotal {
+	byGroup := make(map[string]*groupTotal)
+	for _, entry := range entries {
+		for _, group := range groupOf(entry) {
+			total := byGroup[group]
+			if total == nil {
+				total = &groupTotal{Group: group}
+				byGroup[group] = total
+			}
+			total.Calories += entry.Calories
+			total.Cost += entry.Cost
+			total.Entries++
+		}
+	}
+
+	totals := make([]groupTotal, 0, len(byGroup))
+	for _, total := range byGroup {
+		totals = append(totals, *total)
+	}
+	sort.SliceStable(totals, func(i, j int) bool {
+		if totals[i].Calories != totals[j].Calories {
+			return totals[i].Calories > totals[j].Calories
+		}
+		return totals[i].Group < totals[j].Group
+	})
+	return totals
+}
+
+// entryCategory puts each entry in exactly one group, its food's category
+func entryCategory(entry DiaryEntry) []string {
+	if food := getFoodByID(entry.FoodID); food != nil {
+		return []string{food.categoryLabel()}
+	}
+	return []string{uncategorised}
+}
+
+// splitTagQuery pulls "#tag" words out of a search, returning the rest of
+// the query and the groups asked for
+func splitTagQuery(query string) (string, []string) {
+	var words, groups []string
+	for _, word := range strings.Fields(query) {
+		if strings.HasPrefix(word, "#") && len(word) > 1 {
+			groups = append(groups, normalizeTag(word[1:]))
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " "), groups
+}
+
+// tagsSummary describes a food's category and tags for detail views
+func tagsSummary(f Food) string {
+	text := "Category: " + f.categoryLabel()
+	if len(f.Tags) > 0 {
+		text += "\nTags: " + formatTags(f.Tags)
+	}
+	return text
+}
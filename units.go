@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Built in units every food can be logged in
+const (
+	unitGrams       = "g"
+	unitMillilitres = "ml"
+)
+
+// PortionUnit is a named amount of a food, such as "1 egg = 50 g" or
+// "1 cup = 240 ml". Volumes are converted with the food's density.
+type PortionUnit struct {
+	Name        string  `json:"name"`
+	Grams       float64 `json:"grams,omitempty"`
+	Millilitres float64 `json:"millilitres,omitempty"`
+}
+
+// density is grams per millilitre, assuming water when the food has none
+func (f Food) density() float64 {
+	if f.Density > 0 {
+		return f.Density
+	}
+	return 1
+}
+
+// unitNames lists the units a food can be logged in, grams first
+func (f Food) unitNames() []string {
+	names := []string{unitGrams, unitMillilitres}
+	for _, u := range f.Units {
+		names = append(names, u.Name)
+	}
+	return names
+}
+
+// gramsPerUnit converts one of a unit into grams of the food
+func (f Food) gramsPerUnit(unit string) (float64, error) {
+	switch strings.ToLower(unit) {
+	case "", unitGrams:
+		return 1, nil
+	case unitMillilitres:
+		return f.density(), nil
+	}
+
+	for _, u := range f.Units {
+		if strings.EqualFold(u.Name, unit) {
+			if u.Millilitres > 0 {
+				return u.Millilitres * f.density(), nil
+			}
+			return u.Grams, nil
+		}
+	}
+	return 0, fmt.Errorf("%s has no unit called %q", f.Name, unit)
+}
+
+// parsePortionUnits reads one unit per line in the form "egg = 50 g" or
+// "cup = 240 ml". Unit names are matched ignoring case, so each can only be
+// given once.
+func parsePortionUnits(text string) ([]PortionUnit, error) {
+	var units []PortionUnit
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("unit %q should look like \"egg = 50 g\"", line)
+		}
+
+		name := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(parts[0]), "1 "))
+		if name == "" || strings.EqualFold(name, unitGrams) || strings.EqualFold(name, unitMillilitres) {
+			return nil, fmt.Errorf("unit %q needs a name other than g or ml", line)
+		}
+		for _, u := range units {
+			if strings.EqualFold(u.Name, name) {
+				return nil, fmt.Errorf("unit %q repeats the unit %q", line, u.Name)
+			}
+		}
+
+		fields := strings.Fields(parts[1])
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("unit %q should give an amount in g or ml", line)
+		}
+		amount, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil || amount <= 0 {
+			return nil, fmt.Errorf("unit %q has an invalid amount", line)
+		}
+
+		unit := PortionUnit{Name: name}
+		switch strings.ToLower(fields[1]) {
+		case unitGrams:
+			unit.Grams = amount
+		case unitMillilitres:
+			unit.Millilitres = amount
+		default:
+			return nil, fmt.Errorf("unit %q should be in g or ml", line)
+		}
+		units = append(units, unit)
+	}
+	return units, nil
+}
+
+// servingUnit is the unit recipes with a serving count are logged in
+const servingUnit = "serving"
+
+// setServingUnit keeps a recipe food's "serving" unit in step with its yield
+func setServingUnit(food *Food, servings int) {
+	units := make([]PortionUnit, 0, len(food.Units)+1)
+	for _, u := range food.Units {
+		if !strings.EqualFold(u.Name, servingUnit) {
+			units = append(units, u)
+		}
+	}
+	if servings > 0 && food.Quantity > 0 {
+		units = append(units, PortionUnit{Name: servingUnit, Grams: float64(food.Quantity) / float64(servings)})
+	}
+	if len(units) == 0 {
+		units = nil
+	}
+	food.Units = units
+}
+
+// parseDensity reads an optional density in g/ml, 0 when left blank
+func parseDensity(text string) (float64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, nil
+	}
+	density, err := strconv.ParseFloat(text, 64)
+	if err != nil || density <= 0 {
+		return 0, fmt.Errorf("invalid density. Please enter g/ml, e.g. 1.03")
+	}
+	return density, nil
+}
+
+func formatDensity(density float64) string {
+	if density <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(density, 'f', -1, 64)
+}
+
+func formatPortionUnits(units []PortionUnit) string {
+	lines := make([]string, 0, len(units))
+	for _, u := range units {
+		if u.Millilitres > 0 {
+			lines = append(lines, fmt.Sprintf("%s = %s ml", u.Name, strconv.FormatFloat(u.Millilitres, 'f', -1, 64)))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s = %s g", u.Name, strconv.FormatFloat(u.Grams, 'f', -1, 64)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newDiaryEntryInUnit prices an amount of food given in any of its units
+func newDiaryEntryInUnit(food Food, amount float64, unit, date string) (DiaryEntry, error) {
+	perUnit, err := food.gramsPerUnit(unit)
+	if err != nil {
+		return DiaryEntry{}, err
+	}
+
+	entry := newDiaryEntry(food, amount*perUnit, date)
+	if unit != "" && unit != unitGrams {
+		entry.Amount = amount
+		entry.Unit = unit
+	}
+	return entry, nil
+}
+
+// amountLabel shows an entry in the unit it was logged with
+func (e DiaryEntry) amountLabel() string {
+	if e.Unit == "" {
+		return fmt.Sprintf("%dg", e.Quantity)
+	}
+	amount := strconv.FormatFloat(math.Round(e.Amount*100)/100, 'f', -1, 64)
+	return fmt.Sprintf("%s %s (%dg)", amount, e.Unit, e.Quantity)
+}
+
+// loggedAmount is the amount and unit an entry was logged with
+func (e DiaryEntry) loggedAmount() (float64, string) {
+	if e.Unit == "" {
+		return float64(e.Quantity), unitGrams
+	}
+	return e.Amount, e.Unit
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const waterFile = "water_data.json"
+
+// Quick-add sizes in millilitres
+const (
+	glassMillilitres   = 250
+	bottleMillilitres  = 500
+	defaultWaterTarget = 2000
+)
+
+type WaterEntry struct {
+	ID          int    `json:"id"`
+	Date        string `json:"date"`           // YYYY-MM-DD
+	Time        string `json:"time,omitempty"` // HH:MM
+	Millilitres int    `json:"millilitres"`
+	Drink       string `json:"drink,omitempty"` // water when empty
+}
+
+type WaterLog struct {
+	Target  int          `json:"target"` // millilitres a day
+	Entries []WaterEntry `json:"entries"`
+}
+
+var waterLog = WaterLog{Target: defaultWaterTarget}
+
+// Save and load functions for the water log
+func saveWaterToFile() error {
+	file, err := os.Create(waterFile)
+	if err != nil {
+		return fmt.Errorf("error creating water file: %v", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(waterLog); err != nil {
+		return fmt.Errorf("error encoding water data: %v", err)
+	}
+	return nil
+}
+
+func loadWaterFromFile() error {
+	file, err := os.Open(waterFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			waterLog = WaterLog{Target: defaultWaterTarget, Entries: make([]WaterEntry, 0)}
+			return nil
+		}
+		return fmt.Errorf("error opening water file: %v", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	if err := decoder.Decode(&waterLog); err != nil {
+		return fmt.Errorf("error decoding water data: %v", err)
+	}
+	if waterLog.Target <= 0 {
+		waterLog.Target = defaultWaterTarget
+	}
+	return nil
+}
+
+func nextWaterEntryID() int {
+	maxID := 0
+	for _, entry := range waterLog.Entries {
+		if entry.ID > maxID {
+			maxID = entry.ID
+		}
+	}
+	return maxID + 1
+}
+
+// waterOn totals the millilitres drunk on date
+func waterOn(date string) int {
+	total := 0
+	for _, entry := range waterLog.Entries {
+		if entry.Date == date {
+			total += entry.Millilitres
+		}
+	}
+	return total
+}
+
+// logWater records a drink and lets open diary windows refresh
+func logWater(date string, millilitres int, drink string) error {
+	if millilitres <= 0 {
+		return fmt.Errorf("please enter an amount in millilitres")
+	}
+
+	entry := WaterEntry{
+		ID:          nextWaterEntryID(),
+		Date:        date,
+		Millilitres: millilitres,
+		Drink:       strings.TrimSpace(drink),
+	}
+	if date == time.Now().Format("2006-01-02") {
+		entry.Time = time.Now().Format("15:04")
+	}
+
+	waterLog.Entries = append(waterLog.Entries, entry)
+	if err := saveWaterToFile(); err != nil {
+		return err
+	}
+	notifyDiaryChanged()
+	return nil
+}
+
+func deleteWaterEntry(id int) error {
+	for i, entry := range waterLog.Entries {
+		if entry.ID == id {
+			waterLog.Entries = append(waterLog.Entries[:i], waterLog.Entries[i+1:]...)
+			if err := saveWaterToFile(); err != nil {
+				return err
+			}
+			notifyDiaryChanged()
+			return nil
+		}
+	}
+	return fmt.Errorf("water entry %d not found", id)
+}
+
+// waterProgressText describes a day's intake against the target
+func waterProgressText(total int) string {
+	if total >= waterLog.Target {
+		return fmt.Sprintf("Water: %dml of %dml target, reached", total, waterLog.Target)
+	}
+	return fmt.Sprintf("Water: %dml of %dml target, %dml to go", total, waterLog.Target, waterLog.Target-total)
+}
+
+func (e WaterEntry) label() string {
+	drink := e.Drink
+	if drink == "" {
+		drink = "Water"
+	}
+	text := fmt.Sprintf("%s: %dml", drink, e.Millilitres)
+	if e.Time != "" {
+		text = e.Time + " " + text
+	}
+	return text
+}
+
+func showWaterWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Water")
+
+	dateEntry := widget.NewEntry()
+	dateEntry.SetText(time.Now().Format("2006-01-02"))
+	calendarBtn := widget.NewButton("📅", func() {
+		initial, err := time.Parse("2006-01-02", dateEntry.Text)
+		if err != nil {
+			initial = time.Now()
+		}
+		showDatePicker(window, initial, func(picked time.Time) {
+			dateEntry.SetText(picked.Format("2006-01-02"))
+		})
+	})
+
+	progress := widget.NewProgressBar()
+	progressLabel := widget.NewLabel("")
+	statusLabel := widget.NewLabel("")
+
+	var dayEntries []WaterEntry
+	entriesList := widget.NewList(
+		func() int { return len(dayEntries) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(dayEntries[id].label())
+		},
+	)
+
+	selectedDate := func() (string, bool) {
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(dateEntry.Text))
+		if err != nil {
+			return "", false
+		}
+		return date.Format("2006-01-02"), true
+	}
+
+	refresh := func() {
+		date, ok := selectedDate()
+		if !ok {
+			progressLabel.SetText("Please enter the date as YYYY-MM-DD")
+			return
+		}
+
+		dayEntries = nil
+		for _, entry := range waterLog.Entries {
+			if entry.Date == date {
+				dayEntries = append(dayEntries, entry)
+			}
+		}
+		sort.SliceStable(dayEntries, func(i, j int) bool {
+			return dayEntries[i].Time < dayEntries[j].Time
+		})
+		entriesList.UnselectAll()
+		entriesList.Refresh()
+
+		total := waterOn(date)
+		progress.Max = float64(waterLog.Target)
+		progress.SetValue(math.Min(float64(total), float64(waterLog.Target)))
+		progressLabel.SetText(waterProgressText(total))
+	}
+	dateEntry.OnChanged = func(string) {
+		refresh()
+	}
+
+	entriesList.OnSelected = func(id widget.ListItemID) {
+		entry := dayEntries[id]
+		dialog.ShowConfirm("Delete Drink", fmt.Sprintf("Delete %s?", entry.label()), func(ok bool) {
+			if ok {
+				if err := deleteWaterEntry(entry.ID); err != nil {
+					statusLabel.SetText("Error saving water log: " + err.Error())
+				}
+			}
+			refresh()
+		}, window)
+	}
+
+	add := func(millilitres int, drink string) {
+		date, ok := selectedDate()
+		if !ok {
+			statusLabel.SetText("Please enter the date as YYYY-MM-DD")
+			return
+		}
+		if err := logWater(date, millilitres, drink); err != nil {
+			statusLabel.SetText(err.Error())
+			return
+		}
+		statusLabel.SetText(fmt.Sprintf("Added %dml", millilitres))
+	}
+
+	glassBtn := widget.NewButton(fmt.Sprintf("+ Glass (%dml)", glassMillilitres), func() {
+		add(glassMillilitres, "")
+	})
+	bottleBtn := widget.NewButton(fmt.Sprintf("+ Bottle (%dml)", bottleMillilitres), func() {
+		add(bottleMillilitres, "")
+	})
+
+	amountEntry := widget.NewEntry()
+	amountEntry.SetPlaceHolder("Millilitres")
+	drinkEntry := widget.NewEntry()
+	drinkEntry.SetPlaceHolder("Drink (optional, e.g. Tea)")
+	addBtn := widget.NewButton("Add Drink", func() {
+		millilitres, err := strconv.Atoi(strings.TrimSpace(amountEntry.Text))
+		if err != nil || millilitres <= 0 {
+			statusLabel.SetText("Please enter an amount in millilitres")
+			return
+		}
+		add(millilitres, drinkEntry.Text)
+		amountEntry.SetText("")
+		drinkEntry.SetText("")
+	})
+
+	targetEntry := widget.NewEntry()
+	targetEntry.SetText(strconv.Itoa(waterLog.Target))
+	targetBtn := widget.NewButton("Set Target", func() {
+		target, err := strconv.Atoi(strings.TrimSpace(targetEntry.Text))
+		if err != nil || target <= 0 {
+			statusLabel.SetText("Please enter a daily target in millilitres")
+			return
+		}
+		waterLog.Target = target
+		if err := saveWaterToFile(); err != nil {
+			statusLabel.SetText("Error saving water log: " + err.Error())
+			return
+		}
+		statusLabel.SetText(fmt.Sprintf("Daily target set to %dml", target))
+		notifyDiaryChanged()
+	})
+
+	// Quick-adds from other windows show up here too
+	removeListener := addDiaryListener(refresh)
+	window.SetOnClosed(removeListener)
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	entriesScroll := container.NewScroll(entriesList)
+	entriesScroll.SetMinSize(fyne.NewSize(360, 160))
+
+	content := container.NewVBox(
+		widget.NewLabelWithStyle("Water", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		container.NewBorder(nil, nil, nil, calendarBtn, dateEntry),
+		progress,
+		progressLabel,
+		container.NewGridWithColumns(2, glassBtn, bottleBtn),
+		widget.NewForm(
+			widget.NewFormItem("Amount (ml)", amountEntry),
+			widget.NewFormItem("Drink", drinkEntry),
+		),
+		addBtn,
+		widget.NewLabel("Drinks (tap to delete)"),
+		entriesScroll,
+		widget.NewForm(widget.NewFormItem("Daily target (ml)", container.NewBorder(nil, nil, nil, targetBtn, targetEntry))),
+		statusLabel,
+		backBtn,
+	)
+
+	refresh()
+	window.SetContent(content)
+	window.Resize(fyne.NewSize(400, 600))
+	window.Show()
+	return window
+}
@@ -0,0 +1,504 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const weightFile = "weight_data.json"
+
+const (
+	// trendSmoothing is how much of each day's weigh-in moves the trend
+	trendSmoothing = 0.1
+	// caloriesPerKg is the energy in a kilogram of body weight change
+	caloriesPerKg = 7700
+	// tdeeWindowDays is how far back the TDEE estimate looks
+	tdeeWindowDays = 28
+	// tdeeMinDays is how many days of weigh-ins and food logs it needs
+	tdeeMinDays = 14
+)
+
+// WeightEntry is a weigh-in in kilograms, with optional measurements in
+// centimetres
+type WeightEntry struct {
+	ID      int     `json:"id"`
+	Date    string  `json:"date"` // YYYY-MM-DD
+	Weight  float64 `json:"weight"`
+	Waist   float64 `json:"waist,omitempty"`
+	Hips    float64 `json:"hips,omitempty"`
+	Chest   float64 `json:"chest,omitempty"`
+	BodyFat float64 `json:"body_fat,omitempty"` // percent
+}
+
+type WeightLog struct {
+	GoalWeight float64       `json:"goal_weight,omitempty"`
+	Entries    []WeightEntry `json:"entries"`
+}
+
+var weightLog WeightLog
+
+// Save and load functions for the weight log
+func saveWeightToFile() error {
+	file, err := os.Create(weightFile)
+	if err != nil {
+		return fmt.Errorf("error creating weight file: %v", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(weightLog); err != nil {
+		return fmt.Errorf("error encoding weight data: %v", err)
+	}
+	return nil
+}
+
+func loadWeightFromFile() error {
+	file, err := os.Open(weightFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			weightLog = WeightLog{Entries: make([]WeightEntry, 0)}
+			return nil
+		}
+		return fmt.Errorf("error opening weight file: %v", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	if err := decoder.Decode(&weightLog); err != nil {
+		return fmt.Errorf("error decoding weight data: %v", err)
+	}
+	return nil
+}
+
+func nextWeightEntryID() int {
+	maxID := 0
+	for _, entry := range weightLog.Entries {
+		if entry.ID > maxID {
+			maxID = entry.ID
+		}
+	}
+	return maxID + 1
+}
+
+// trendPoint is the smoothed weight on a day with a weigh-in
+type trendPoint struct {
+	Date   string
+	Weight float64
+	Trend  float64
+}
+
+// weightTrend smooths the weigh-ins with an exponentially weighted moving
+// average, so day to day swings in water weight don't hide the real
+// direction. Several weigh-ins on one day are averaged first, and a weigh-in
+// after a gap moves the trend as far as that many daily ones would.
+func weightTrend() []trendPoint {
+	byDate := make(map[string][]float64)
+	for _, entry := range weightLog.Entries {
+		if entry.Weight > 0 {
+			byDate[entry.Date] = append(byDate[entry.Date], entry.Weight)
+		}
+	}
+
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	points := make([]trendPoint, 0, len(dates))
+	for i, date := range dates {
+		var sum float64
+		for _, w := range byDate[date] {
+			sum += w
+		}
+		weight := sum / float64(len(byDate[date]))
+
+		trend := weight
+		if i > 0 {
+			previous := points[i-1]
+			smoothing := trendSmoothing
+			prevDay, err1 := time.Parse("2006-01-02", previous.Date)
+			day, err2 := time.Parse("2006-01-02", date)
+			if err1 == nil && err2 == nil {
+				days := math.Max(1, math.Round(day.Sub(prevDay).Hours()/24))
+				smoothing = 1 - math.Pow(1-trendSmoothing, days)
+			}
+			trend = previous.Trend + smoothing*(weight-previous.Trend)
+		}
+		points = append(points, trendPoint{Date: date, Weight: weight, Trend: trend})
+	}
+	return points
+}
+
+// tdeeEstimate is maintenance calories worked out from what was eaten and
+// how weight moved over the same days
+type tdeeEstimate struct {
+	TDEE          float64
+	AverageIntake float64
+	KgPerWeek     float64 // weight change, negative when losing
+	Days          int
+}
+
+// weightSlope fits a straight line through the weigh-ins by least squares
+// and returns its slope in kg per day
+func weightSlope(points []trendPoint) float64 {
+	first, _ := time.Parse("2006-01-02", points[0].Date)
+	var sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		date, _ := time.Parse("2006-01-02", p.Date)
+		x := date.Sub(first).Hours() / 24
+		sumX += x
+		sumY += p.Weight
+		sumXY += x * p.Weight
+		sumXX += x * x
+	}
+	n := float64(len(points))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// estimateTDEE compares average logged intake with the weight change over
+// the last tdeeWindowDays. The change is the least squares slope of the
+// weigh-ins rather than the smoothed trend, which lags behind and depends on
+// where it started. Only days with food logged count towards intake, so gaps
+// in the diary don't read as fasting.
+func estimateTDEE(today time.Time) (tdeeEstimate, error) {
+	points := weightTrend()
+	if len(points) < 2 {
+		return tdeeEstimate{}, fmt.Errorf("log at least two weigh-ins to estimate TDEE")
+	}
+
+	endDate := today.Format("2006-01-02")
+	startDate := today.AddDate(0, 0, -tdeeWindowDays).Format("2006-01-02")
+
+	// Measure between the first and last weigh-ins in the window
+	startIdx, endIdx := -1, -1
+	for i, p := range points {
+		if p.Date < startDate || p.Date > endDate {
+			continue
+		}
+		if startIdx < 0 {
+			startIdx = i
+		}
+		endIdx = i
+	}
+	if startIdx < 0 {
+		return tdeeEstimate{}, fmt.Errorf("no weigh-ins in the last %d days", tdeeWindowDays)
+	}
+
+	first, _ := time.Parse("2006-01-02", points[startIdx].Date)
+	last, _ := time.Parse("2006-01-02", points[endIdx].Date)
+	span := int(math.Round(last.Sub(first).Hours() / 24))
+	if span < tdeeMinDays {
+		return tdeeEstimate{}, fmt.Errorf("need weigh-ins spread over %d days, have %d", tdeeMinDays, span)
+	}
+
+	caloriesByDay := make(map[string]float64)
+	for _, entry := range dailyDiary.Entries {
+		if entry.Date >= points[startIdx].Date && entry.Date <= points[endIdx].Date {
+			caloriesByDay[entry.Date] += entry.Calories
+		}
+	}
+	if len(caloriesByDay) < tdeeMinDays {
+		return tdeeEstimate{}, fmt.Errorf("need food logged on %d days, have %d", tdeeMinDays, len(caloriesByDay))
+	}
+
+	var intake float64
+	for _, calories := range caloriesByDay {
+		intake += calories
+	}
+	intake /= float64(len(caloriesByDay))
+
+	kgPerDay := weightSlope(points[startIdx : endIdx+1])
+	return tdeeEstimate{
+		TDEE:          intake - kgPerDay*caloriesPerKg,
+		AverageIntake: intake,
+		KgPerWeek:     kgPerDay * 7,
+		Days:          span,
+	}, nil
+}
+
+// weeksToGoal projects how long the current trend takes to reach the goal
+// weight. It is false when there is no goal or the trend is heading away
+// from it.
+func weeksToGoal(trend, goal, kgPerWeek float64) (float64, bool) {
+	if goal <= 0 {
+		return 0, false
+	}
+	remaining := goal - trend
+	if math.Abs(remaining) < 0.05 {
+		return 0, true
+	}
+	if kgPerWeek == 0 || (remaining > 0) != (kgPerWeek > 0) {
+		return 0, false
+	}
+	return remaining / kgPerWeek, true
+}
+
+// weightReport describes trend weight, TDEE and progress to the goal
+func weightReport() string {
+	points := weightTrend()
+	if len(points) == 0 {
+		return "No weigh-ins logged yet"
+	}
+	latest := points[len(points)-1]
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("Trend weight: %.1fkg (last weigh-in %.1fkg on %s)\n", latest.Trend, latest.Weight, latest.Date))
+
+	estimate, err := estimateTDEE(time.Now())
+	if err != nil {
+		report.WriteString("Estimated TDEE: " + err.Error() + "\n")
+	} else {
+		report.WriteString(fmt.Sprintf("Estimated TDEE: %.0f cal/day (eating %.0f, weight %+.2fkg/week over %d days)\n",
+			estimate.TDEE, estimate.AverageIntake, estimate.KgPerWeek, estimate.Days))
+	}
+
+	if goal := weightLog.GoalWeight; goal > 0 {
+		report.WriteString(fmt.Sprintf("Goal weight: %.1fkg, %.1fkg to go\n", goal, math.Abs(goal-latest.Trend)))
+		if err == nil {
+			if weeks, ok := weeksToGoal(latest.Trend, goal, estimate.KgPerWeek); ok {
+				reach := time.Now().AddDate(0, 0, int(math.Ceil(weeks*7)))
+				report.WriteString(fmt.Sprintf("At this rate: %.1f weeks, around %s\n", weeks, reach.Format("2006-01-02")))
+			} else {
+				report.WriteString("At this rate the goal will not be reached\n")
+			}
+		}
+	}
+	return strings.TrimSuffix(report.String(), "\n")
+}
+
+func (e WeightEntry) label() string {
+	text := fmt.Sprintf("%s: %.1fkg", e.Date, e.Weight)
+	if e.Waist > 0 {
+		text += fmt.Sprintf(", waist %.1fcm", e.Waist)
+	}
+	if e.Hips > 0 {
+		text += fmt.Sprintf(", hips %.1fcm", e.Hips)
+	}
+	if e.Chest > 0 {
+		text += fmt.Sprintf(", chest %.1fcm", e.Chest)
+	}
+	if e.BodyFat > 0 {
+		text += fmt.Sprintf(", %.1f%% fat", e.BodyFat)
+	}
+	return text
+}
+
+func showWeightWindow(myApp fyne.App) fyne.Window {
+	window := myApp.NewWindow("Body Weight")
+
+	dateEntry := widget.NewEntry()
+	dateEntry.SetText(time.Now().Format("2006-01-02"))
+	calendarBtn := widget.NewButton("📅", func() {
+		initial, err := time.Parse("2006-01-02", dateEntry.Text)
+		if err != nil {
+			initial = time.Now()
+		}
+		showDatePicker(window, initial, func(picked time.Time) {
+			dateEntry.SetText(picked.Format("2006-01-02"))
+		})
+	})
+
+	weightEntry := widget.NewEntry()
+	weightEntry.SetPlaceHolder("kg")
+	waistEntry := widget.NewEntry()
+	waistEntry.SetPlaceHolder("cm (optional)")
+	hipsEntry := widget.NewEntry()
+	hipsEntry.SetPlaceHolder("cm (optional)")
+	chestEntry := widget.NewEntry()
+	chestEntry.SetPlaceHolder("cm (optional)")
+	bodyFatEntry := widget.NewEntry()
+	bodyFatEntry.SetPlaceHolder("% (optional)")
+
+	goalEntry := widget.NewEntry()
+	goalEntry.SetPlaceHolder("kg")
+	if weightLog.GoalWeight > 0 {
+		goalEntry.SetText(strconv.FormatFloat(weightLog.GoalWeight, 'f', -1, 64))
+	}
+
+	reportLabel := widget.NewLabel("")
+	trendChart := container.NewMax()
+	statusLabel := widget.NewLabel("")
+
+	// Newest weigh-ins first
+	var entries []WeightEntry
+	entriesList := widget.NewList(
+		func() int { return len(entries) },
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(entries[id].label())
+		},
+	)
+
+	refresh := func() {
+		entries = append([]WeightEntry(nil), weightLog.Entries...)
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Date > entries[j].Date
+		})
+		entriesList.UnselectAll()
+		entriesList.Refresh()
+
+		reportLabel.SetText(weightReport())
+
+		points := weightTrend()
+		var chartPoints []chartPoint
+		if len(points) > 0 {
+			start, _ := time.Parse("2006-01-02", points[0].Date)
+			for _, p := range points {
+				date, _ := time.Parse("2006-01-02", p.Date)
+				chartPoints = append(chartPoints, chartPoint{X: date.Sub(start).Hours() / 24, Value: p.Trend, Label: p.Date})
+			}
+		}
+		trendChart.Objects = []fyne.CanvasObject{newLineChart(chartPoints, fyne.NewSize(460, 180), "%.1fkg")}
+		trendChart.Refresh()
+	}
+
+	entriesList.OnSelected = func(id widget.ListItemID) {
+		entry := entries[id]
+		dialog.ShowConfirm("Delete Weigh-in", fmt.Sprintf("Delete %s?", entry.label()), func(ok bool) {
+			if ok {
+				newEntries := make([]WeightEntry, 0, len(weightLog.Entries))
+				for _, other := range weightLog.Entries {
+					if other.ID != entry.ID {
+						newEntries = append(newEntries, other)
+					}
+				}
+				weightLog.Entries = newEntries
+				if err := saveWeightToFile(); err != nil {
+					statusLabel.SetText("Error saving weight log: " + err.Error())
+				}
+			}
+			refresh()
+		}, window)
+	}
+
+	// parseOptional reads a blank field as zero
+	parseOptional := func(text, what string) (float64, error) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return 0, nil
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("Invalid %s. Please enter a number", what)
+		}
+		return value, nil
+	}
+
+	addBtn := widget.NewButton("Log Weigh-in", func() {
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(dateEntry.Text))
+		if err != nil {
+			statusLabel.SetText("Please enter the date as YYYY-MM-DD")
+			return
+		}
+
+		entry := WeightEntry{ID: nextWeightEntryID(), Date: date.Format("2006-01-02")}
+		entry.Weight, err = strconv.ParseFloat(strings.TrimSpace(weightEntry.Text), 64)
+		if err != nil || entry.Weight <= 0 {
+			statusLabel.SetText("Invalid weight. Please enter kilograms")
+			return
+		}
+		for _, field := range []struct {
+			text  string
+			what  string
+			value *float64
+		}{
+			{waistEntry.Text, "waist", &entry.Waist},
+			{hipsEntry.Text, "hips", &entry.Hips},
+			{chestEntry.Text, "chest", &entry.Chest},
+			{bodyFatEntry.Text, "body fat", &entry.BodyFat},
+		} {
+			if *field.value, err = parseOptional(field.text, field.what); err != nil {
+				statusLabel.SetText(err.Error())
+				return
+			}
+		}
+
+		weightLog.Entries = append(weightLog.Entries, entry)
+		if err := saveWeightToFile(); err != nil {
+			statusLabel.SetText("Error saving weight log: " + err.Error())
+			return
+		}
+
+		statusLabel.SetText(fmt.Sprintf("Logged %.1fkg on %s", entry.Weight, entry.Date))
+		weightEntry.SetText("")
+		waistEntry.SetText("")
+		hipsEntry.SetText("")
+		chestEntry.SetText("")
+		bodyFatEntry.SetText("")
+		refresh()
+	})
+
+	goalBtn := widget.NewButton("Set Goal", func() {
+		goal, err := parseOptional(goalEntry.Text, "goal weight")
+		if err != nil {
+			statusLabel.SetText(err.Error())
+			return
+		}
+		weightLog.GoalWeight = goal
+		if err := saveWeightToFile(); err != nil {
+			statusLabel.SetText("Error saving weight log: " + err.Error())
+			return
+		}
+		statusLabel.SetText("Goal weight saved")
+		refresh()
+	})
+
+	// Intake changes move the TDEE estimate
+	removeListener := addDiaryListener(refresh)
+	window.SetOnClosed(removeListener)
+
+	backBtn := widget.NewButton("Back", func() {
+		window.Close()
+	})
+
+	weighInForm := widget.NewForm(
+		widget.NewFormItem("Date", container.NewBorder(nil, nil, nil, calendarBtn, dateEntry)),
+		widget.NewFormItem("Weight", weightEntry),
+		widget.NewFormItem("Waist", waistEntry),
+		widget.NewFormItem("Hips", hipsEntry),
+		widget.NewFormItem("Chest", chestEntry),
+		widget.NewFormItem("Body fat", bodyFatEntry),
+	)
+
+	entriesScroll := container.NewScroll(entriesList)
+	entriesScroll.SetMinSize(fyne.NewSize(460, 150))
+
+	content := container.NewVBox(
+		widget.NewLabelWithStyle("Body Weight", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		reportLabel,
+		widget.NewLabel("Trend weight"),
+		trendChart,
+		widget.NewForm(widget.NewFormItem("Goal weight", container.NewBorder(nil, nil, nil, goalBtn, goalEntry))),
+		widget.NewSeparator(),
+		weighInForm,
+		addBtn,
+		statusLabel,
+		widget.NewLabel("Weigh-ins (tap to delete)"),
+		entriesScroll,
+		backBtn,
+	)
+
+	refresh()
+	window.SetContent(container.NewVScroll(content))
+	window.Resize(fyne.NewSize(500, 750))
+	window.Show()
+	return window
+}